@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/kataras/iris-cli/project"
+	"github.com/kataras/iris-cli/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// iris-cli checksum --repo=author/project@v1.0.0
+// iris-cli checksum --archive=./starter.zip
+func checksumCommand() *cobra.Command {
+	var opts project.Project
+
+	cmd := &cobra.Command{
+		Use:           "checksum",
+		Short:         "Checksum prints the SHA256 of a project's archive, to be verified on installation.",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case opts.Archive != "":
+				opts.Name = path.Base(opts.Archive)
+			case opts.Repo != "":
+				opts.Repo, opts.Version = utils.SplitNameVersion(opts.Repo)
+				opts.Name = path.Base(opts.Repo)
+			default:
+				return fmt.Errorf("the --repo or --archive flag is required")
+			}
+
+			checksum, err := opts.ArchiveChecksum()
+			if err != nil {
+				return err
+			}
+
+			cmd.Println(checksum)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Repo, "repo", opts.Repo, "--repo=author/project@version of the archive")
+	cmd.Flags().StringVar(&opts.Archive, "archive", opts.Archive, "--archive=local zip or tar.gz file")
+	cmd.Flags().BoolVar(&opts.NoCache, "no-cache", opts.NoCache, "--no-cache to always download the archive")
+
+	return cmd
+}
@@ -2,9 +2,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/kataras/iris-cli/project"
+
 	"github.com/spf13/cobra"
 )
 
@@ -35,10 +40,67 @@ Complete documentation is available at https://github.com/kataras/iris-cli`,
 	rootCmd.AddCommand(newCommand())
 	rootCmd.AddCommand(runCommand())
 	rootCmd.AddCommand(addCommand())
+	rootCmd.AddCommand(generateCommand())
+	rootCmd.AddCommand(uninstallCommand())
+	rootCmd.AddCommand(upgradeCommand())
+	rootCmd.AddCommand(upgradeIrisCommand())
+	rootCmd.AddCommand(listCommand())
+	rootCmd.AddCommand(checksumCommand())
+	rootCmd.AddCommand(doctorCommand())
 
 	return rootCmd
 }
 
+// Exit codes of the command line interface, other failures exit with 1.
+const (
+	ExitRepoNotFound     = 2
+	ExitAccessDenied     = 3
+	ExitNetwork          = 4
+	ExitChecksumMismatch = 5
+	ExitNotModule        = 6
+	ExitIllegalPath      = 7
+	ExitFilesExist       = 8
+	ExitNotCached        = 9
+	ExitTooLarge         = 10
+	ExitRateLimited      = 11
+	ExitInvalidPassword  = 12
+	ExitCaseCollision    = 13
+)
+
+// ExitCode returns the exit code of the command line interface for "err".
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, project.ErrRateLimited):
+		return ExitRateLimited
+	case errors.Is(err, project.ErrRepoNotFound):
+		return ExitRepoNotFound
+	case errors.Is(err, project.ErrAccessDenied):
+		return ExitAccessDenied
+	case errors.Is(err, project.ErrNetwork):
+		return ExitNetwork
+	case errors.Is(err, project.ErrChecksumMismatch):
+		return ExitChecksumMismatch
+	case errors.Is(err, project.ErrNotModule):
+		return ExitNotModule
+	case errors.Is(err, project.ErrIllegalPath):
+		return ExitIllegalPath
+	case errors.Is(err, project.ErrFilesExist):
+		return ExitFilesExist
+	case errors.Is(err, project.ErrNotCached):
+		return ExitNotCached
+	case errors.Is(err, project.ErrTooLarge):
+		return ExitTooLarge
+	case errors.Is(err, project.ErrInvalidPassword):
+		return ExitInvalidPassword
+	case errors.Is(err, project.ErrCaseCollision):
+		return ExitCaseCollision
+	default:
+		return 1
+	}
+}
+
 var shared = make(map[string]map[string]interface{}) // key = root command/app and value a map of key-value pair.
 
 // SetValue sets a value to the shared store for specific app based on the root "cmd".
@@ -94,6 +156,30 @@ func RunCommand(from *cobra.Command, commandToRun string, args ...string) error
 	return nil
 }
 
+// interruptContext returns a context which is canceled on the first interrupt or termination signal, e.g. CTRL/CMD+C,
+// so a running installation removes its temporary and partially installed files before the command exits.
+// A second signal terminates the program immediately.
+// Usage: ctx, stop := interruptContext(); defer stop()
+func interruptContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-ch:
+			signal.Stop(ch)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(ch)
+		cancel()
+	}
+}
+
 // showIndicator writes a loader to "cmd".
 // Usage: defer showIndicator(cmd)()
 func showIndicator(cmd *cobra.Command) func() {
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/kataras/iris-cli/project"
+	"github.com/kataras/iris-cli/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// iris-cli doctor
+// iris-cli doctor ./myproject
+func doctorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "doctor",
+		Short:         "Doctor verifies that a project builds.",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			dir = utils.Dest(dir)
+
+			// The go.mod of a project installed by the new command may be inside a subdirectory.
+			if p, err := project.ReadManifest(dir); err == nil {
+				dir = filepath.Join(dir, filepath.FromSlash(p.ModuleDir))
+			}
+
+			// The error distinguishes the missing dependencies from the compile errors, see `project.BuildError`.
+			if err := project.Build(context.Background(), dir); err != nil {
+				return err
+			}
+
+			cmd.Printf("Project <%s> builds.\n", dir)
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kataras/iris-cli/generate"
+	"github.com/kataras/iris-cli/utils"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+// iris-cli generate controller User
+// iris-cli generate controller --dest=./controllers --package=controllers User
+// iris-cli generate handler --method=POST --path=/api/ping Ping
+// iris-cli generate dockerfile --port=8080
+// iris-cli generate makefile --binary=server --port=8080
+// iris-cli generate embed ./public
+// iris-cli generate embed --route=/assets ./public
+// iris-cli generate env --port=8080 --db-url=postgres://localhost:5432/app --loader
+func generateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "generate",
+		Aliases:       []string{"gen"},
+		Short:         "Generate creates Iris source files.",
+		SilenceErrors: true,
+	}
+
+	cmd.AddCommand(generateControllerCommand())
+	cmd.AddCommand(generateHandlerCommand())
+	cmd.AddCommand(generateDockerfileCommand())
+	cmd.AddCommand(generateMakefileCommand())
+	cmd.AddCommand(generateEmbedCommand())
+	cmd.AddCommand(generateEnvCommand())
+
+	return cmd
+}
+
+func generateControllerCommand() *cobra.Command {
+	opts := generate.Controller{
+		Dest: "./",
+	}
+
+	cmd := &cobra.Command{
+		Use:           "controller",
+		Short:         "Controller generates an Iris MVC controller file.",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("argument controller name is required")
+			}
+
+			opts.Name = args[0]
+			fpath, err := opts.Generate()
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Controller <%s> created.\n", fpath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Dest, "dest", opts.Dest, "--dest=directory or file path of the controller")
+	cmd.Flags().StringVar(&opts.Package, "package", opts.Package, "--package=empty to be resolved by the destination's files")
+	cmd.Flags().BoolVar(&opts.Force, "force", opts.Force, "--force to overwrite an existing file")
+
+	return cmd
+}
+
+func generateHandlerCommand() *cobra.Command {
+	opts := generate.Handler{
+		Method: "GET",
+		Dest:   "./",
+	}
+
+	cmd := &cobra.Command{
+		Use:           "handler",
+		Short:         "Handler generates an Iris route handler file.",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("argument handler name is required")
+			}
+
+			opts.Name = args[0]
+			fpath, err := opts.Generate()
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Handler <%s> created.\n", fpath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Method, "method", opts.Method, "--method=HTTP method of the route")
+	cmd.Flags().StringVar(&opts.Path, "path", opts.Path, "--path=empty for /handler-name")
+	cmd.Flags().StringVar(&opts.Dest, "dest", opts.Dest, "--dest=directory or file path of the handler")
+	cmd.Flags().StringVar(&opts.Package, "package", opts.Package, "--package=empty to be resolved by the destination's files")
+	cmd.Flags().BoolVar(&opts.Force, "force", opts.Force, "--force to overwrite an existing file")
+
+	return cmd
+}
+
+func generateDockerfileCommand() *cobra.Command {
+	opts := generate.Dockerfile{
+		Dir:  "./",
+		Port: 8080,
+	}
+
+	cmd := &cobra.Command{
+		Use:           "dockerfile",
+		Short:         "Dockerfile generates a multi-stage Dockerfile for the project.",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fpath, err := opts.Generate()
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Dockerfile <%s> created.\n", fpath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Dir, "dir", opts.Dir, "--dir=project directory which contains the go.mod file")
+	cmd.Flags().StringVar(&opts.GoVersion, "go", opts.GoVersion, "--go=version of the golang build image, defaults to the go directive of go.mod")
+	cmd.Flags().IntVar(&opts.Port, "port", opts.Port, "--port=port of the application to expose")
+	cmd.Flags().BoolVar(&opts.Force, "force", opts.Force, "--force to overwrite an existing Dockerfile")
+
+	return cmd
+}
+
+func generateMakefileCommand() *cobra.Command {
+	opts := generate.Makefile{
+		Dir:  "./",
+		Port: 8080,
+	}
+
+	cmd := &cobra.Command{
+		Use:           "makefile",
+		Short:         "Makefile generates a Makefile with build, run, test and tidy targets.",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fpath, err := opts.Generate()
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Makefile <%s> created.\n", fpath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Dir, "dir", opts.Dir, "--dir=project directory which contains the go.mod file")
+	cmd.Flags().StringVar(&opts.Binary, "binary", opts.Binary, "--binary=empty for the module's name")
+	cmd.Flags().IntVar(&opts.Port, "port", opts.Port, "--port=default PORT of the run target")
+	cmd.Flags().BoolVar(&opts.Force, "force", opts.Force, "--force to overwrite an existing Makefile")
+
+	return cmd
+}
+
+func generateEmbedCommand() *cobra.Command {
+	opts := generate.Embed{
+		Dest: "./",
+	}
+
+	cmd := &cobra.Command{
+		Use:           "embed",
+		Short:         "Embed generates a go:embed file which serves an assets directory (go 1.16+).",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("argument assets directory is required")
+			}
+
+			opts.Dir = args[0]
+			fpath, err := opts.Generate()
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Embed <%s> created.\n", fpath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Route, "route", opts.Route, "--route=empty for /directory-name")
+	cmd.Flags().StringVar(&opts.Dest, "dest", opts.Dest, "--dest=directory or file path of the embed file")
+	cmd.Flags().StringVar(&opts.Package, "package", opts.Package, "--package=empty to be resolved by the destination's files")
+	cmd.Flags().BoolVar(&opts.Force, "force", opts.Force, "--force to overwrite an existing file")
+
+	return cmd
+}
+
+func generateEnvCommand() *cobra.Command {
+	var (
+		opts = generate.Env{
+			Dir:  "./",
+			Port: 8080,
+		}
+		interactive = true
+	)
+
+	cmd := &cobra.Command{
+		Use:           "env",
+		Short:         "Env generates a .env configuration file and a .env.example one.",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interactive && utils.IsTerminal(os.Stdin) {
+				qs := []*survey.Question{
+					{
+						Name:   "port",
+						Prompt: &survey.Input{Message: "PORT:", Default: fmt.Sprint(opts.Port)},
+					},
+					{
+						Name:   "dburl",
+						Prompt: &survey.Input{Message: "DB_URL:", Default: opts.DBURL},
+					},
+				}
+
+				if err := survey.Ask(qs, &opts); err != nil {
+					return err
+				}
+			}
+
+			files, err := opts.Generate()
+			for _, fpath := range files {
+				cmd.Printf("Env <%s> created.\n", fpath)
+			}
+
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Dir, "dir", opts.Dir, "--dir=project directory")
+	cmd.Flags().IntVar(&opts.Port, "port", opts.Port, "--port=value of the PORT key")
+	cmd.Flags().StringVar(&opts.DBURL, "db-url", opts.DBURL, "--db-url=value of the DB_URL key")
+	cmd.Flags().StringVar(&opts.Secret, "secret", opts.Secret, "--secret=empty for a random SECRET key")
+	cmd.Flags().StringToStringVar(&opts.Vars, "var", opts.Vars, "--var=REDIS_ADDR=localhost:6379 extra keys")
+	cmd.Flags().BoolVar(&opts.Loader, "loader", opts.Loader, "--loader to write an env.go which loads the .env with godotenv")
+	cmd.Flags().StringVar(&opts.Package, "package", opts.Package, "--package=empty to be resolved by the directory's files")
+	cmd.Flags().BoolVar(&interactive, "interactive", interactive, "--interactive=false to never prompt for the values")
+	cmd.Flags().BoolVar(&opts.Force, "force", opts.Force, "--force to overwrite an existing .env.example and env.go, never the .env")
+
+	return cmd
+}
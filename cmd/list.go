@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/kataras/iris-cli/project"
+
+	"github.com/spf13/cobra"
+)
+
+// iris-cli list
+// iris-cli list --endpoint=./templates.json
+func listCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "list",
+		Short:         "List prints the installable starter templates.",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			templates, err := project.Templates()
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			for _, t := range templates {
+				repo := t.Repo
+				if t.Version != "master" {
+					repo += "@" + t.Version
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", t.Name, repo, t.Description)
+			}
+
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&project.TemplatesEndpoint, "endpoint", project.TemplatesEndpoint, "--endpoint=URL or local file of the templates index")
+
+	return cmd
+}
@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"io"
-	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/kataras/iris-cli/project"
 	"github.com/kataras/iris-cli/utils"
@@ -15,39 +18,168 @@ import (
 
 // iris-cli new --registry=./_testfiles/registry.json
 // iris-cli new --registry=./_testfiles/registry.json --dest=%GOPATH%/github.com/author --module=github.com/author/neffos github.com/kataras/neffos@master
+// iris-cli new --repo=kataras/neffos@v0.0.14 --module=github.com/author/neffos
+// iris-cli new --repo=org/starters --subdir=rest-api
+// iris-cli new . --repo=kataras/neffos
+// iris-cli new --archive=./starter.zip --module=github.com/author/app
+// iris-cli new --github-enterprise=ghe.mycorp.com --repo=ghe.mycorp.com/team/app
+// iris-cli new --print --repo=kataras/neffos@v0.0.14
+// iris-cli new --rewrites --repo=kataras/neffos --module=github.com/author/neffos
+// iris-cli new --goproxy --repo=github.com/kataras/neffos@v0.0.14 --module=github.com/author/neffos
 func newCommand() *cobra.Command {
 	var (
 		reg = project.NewRegistry()
 
 		opts = project.Project{
-			Version: "master",
-			Dest:    "./",
-			Reader: func(r io.Reader) ([]byte, error) {
-				tmpl := `{{etime . "%s elapsed"}} {{speed . }}`
-				// Content-Length is not available
-				// on Github release download response.
-				bar := pb.ProgressBarTemplate(tmpl).Start64(0).SetMaxWidth(45)
-				defer bar.Finish()
-
-				b, err := ioutil.ReadAll(bar.NewProxyReader(r))
-				bar.SetTemplateString(`{{etime . "%s elapsed"}} [{{string . "all_bytes" | green}}]`)
-				bar.Set("all_bytes", formatByteLength(len(b)))
-				return b, err
-			},
+			Version:  "master",
+			Dest:     "./",
+			Progress: downloadProgress(),
 		}
 	)
 
+	var (
+		githubEnterprise []string
+		verbose          bool
+		yes              bool
+		printOnly        bool
+		rewrites         bool
+		keepCI           bool
+		interactive      = true
+	)
+
 	cmd := &cobra.Command{
 		Use:           "new",
 		Short:         "New creates a new starter kit project.",
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// Do not inherit the template's CI by default.
+			opts.ExcludeCI = !keepCI
+
+			ctx, stop := interruptContext()
+			defer stop()
+
+			if len(args) > 0 && args[0] == "." {
+				// Extract into the current directory, e.g. a newly created and empty one.
+				opts.Dest = "."
+				args = args[1:]
+			}
+
+			// Prompt for the missing options only if the input is a terminal, so scripts never block.
+			canPrompt := interactive && utils.IsTerminal(os.Stdin)
+
+			for _, host := range githubEnterprise {
+				project.RegisterProvider(project.GitHubEnterprise(host))
+			}
+
+			if opts.GitInit {
+				if _, err := exec.LookPath("git"); err != nil {
+					cmd.Println("Warning: git is not installed, the repository will not be initialized.")
+					opts.GitInit = false
+				}
+			}
+
+			if verbose {
+				opts.Logf = func(format string, args ...interface{}) {
+					cmd.Printf(format+"\n", args...)
+				}
+			}
+
+			opts.Confirm = func(commands []string) bool {
+				cmd.Println("The project runs the following commands after installation:")
+				for _, command := range commands {
+					cmd.Printf("  %s\n", command)
+				}
+
+				if yes {
+					return true
+				}
+
+				ok := false
+				if !canPrompt {
+					cmd.Println("Warning: the commands are skipped, use --yes to run them in non-interactive mode.")
+					return false
+				}
+
+				if err := survey.AskOne(&survey.Confirm{Message: "Run them?"}, &ok); err != nil {
+					cmd.Println("Warning: the commands are skipped, use --yes to run them in non-interactive mode.")
+					return false
+				}
+
+				return ok
+			}
+
+			if rewrites {
+				opts.DryRun = true
+				opts.Rewrites = func(path string, occurrences int, rewritten bool) {
+					action := "keep"
+					if rewritten {
+						action = "rewrite"
+					}
+					cmd.Printf("%s\t%d\t%s\n", action, occurrences, path)
+				}
+			} else if opts.DryRun {
+				opts.Preview = func(path string, exists bool) {
+					action := "create"
+					if exists {
+						action = "overwrite"
+					}
+					cmd.Printf("%s\t%s\n", action, path)
+				}
+			}
+
+			if opts.Archive != "" || opts.Dir != "" {
+				// Install from a local zip file or directory.
+				local := project.NewFromDir(opts.Dest, opts.Dir)
+				if opts.Archive != "" {
+					local = project.NewFromArchive(opts.Dest, opts.Archive)
+				}
+				opts.Name = local.Name
+				if printOnly {
+					return printResolved(cmd, &opts)
+				}
+
+				installed, err := opts.Installation(ctx)
+				if err != nil {
+					return err
+				}
+
+				printInstalled(cmd, installed)
+				return nil
+			}
+
+			if opts.Repo != "" {
+				// Install directly from a repository, the version can be a branch, a tag or a commit SHA.
+				opts.Repo, opts.Version = utils.SplitNameVersion(opts.Repo)
+				opts.Name = path.Base(opts.Repo)
+				if printOnly {
+					return printResolved(cmd, &opts)
+				}
+
+				if canPrompt && opts.Module == "" {
+					if err := askRepoOptions(&opts); err != nil {
+						return err
+					}
+				}
+
+				installed, err := opts.Installation(ctx)
+				if err != nil {
+					return err
+				}
+
+				printInstalled(cmd, installed)
+				return nil
+			}
+
 			cmd.Printf("Loading projects from <%s>\n", reg.Endpoint)
 			if err := reg.Load(); err != nil {
 				return err
 			}
 
 			if len(args) == 0 {
+				if !canPrompt {
+					return fmt.Errorf("a project name or the --repo flag is required in non-interactive mode")
+				}
+
 				err := survey.AskOne(&survey.Select{Message: "Choose a project to install:", Options: reg.Names, PageSize: 10}, &opts.Name)
 				if err != nil {
 					return err
@@ -92,27 +224,164 @@ func newCommand() *cobra.Command {
 				opts.Name, opts.Version = utils.SplitNameVersion(args[0]) // split by @.
 			}
 
+			if printOnly {
+				repo, ok := reg.Exists(opts.Name)
+				if !ok {
+					return fmt.Errorf("project <%s> is not available", opts.Name)
+				}
+
+				resolved := opts
+				resolved.Repo = repo
+				return printResolved(cmd, &resolved)
+			}
+
 			if !utils.Exists(opts.Dest) {
 				cmd.Printf("Directory <%s> will be created.\n", opts.Dest)
 			}
 
-			err := reg.Install(&opts)
+			installed, err := reg.InstallationContext(ctx, &opts)
 			if err != nil {
 				return err
 			}
 
-			// cmd.Printf("Project <%s> created.\n", opts.Dest)
+			printInstalled(cmd, installed)
 			return nil
 		},
 	}
 
+	cmd.Flags().StringVar(&opts.Repo, "repo", opts.Repo, "--repo=author/project@version to install from a repository directly")
+	cmd.Flags().BoolVar(&opts.DefaultBranch, "branch-from-default", opts.DefaultBranch, "--branch-from-default to download the repository's default branch instead of master")
+	cmd.Flags().BoolVar(&opts.GoProxy, "goproxy", opts.GoProxy, "--goproxy to download the module zip of a module path, e.g. github.com/kataras/iris/v12, from the GOPROXY instead of the repository")
+	cmd.Flags().StringVar(&opts.Archive, "archive", opts.Archive, "--archive=local zip or tar.gz file to install from")
+	cmd.Flags().StringVar(&opts.Dir, "dir", opts.Dir, "--dir=local directory to install from")
+	cmd.Flags().StringVar(&opts.Password, "password", opts.Password, "--password=password of a password-protected zip archive")
+	cmd.Flags().StringVar(&opts.Subdir, "subdir", opts.Subdir, "--subdir=extract only a subdirectory of the repository")
+	cmd.Flags().IntVar(&opts.StripComponents, "strip-components", opts.StripComponents, "--strip-components=number of leading path elements to remove, after the root folder")
+	cmd.Flags().StringSliceVar(&opts.Include, "include", opts.Include, "--include=pattern of files to extract, e.g. *.go,views")
+	cmd.Flags().StringSliceVar(&opts.Exclude, "exclude", opts.Exclude, "--exclude=pattern of files to not extract, e.g. .github,docs,*.md")
+	cmd.Flags().BoolVar(&keepCI, "keep-ci", keepCI, "--keep-ci to extract the template's CI and VCS files too, e.g. .github and .gitlab-ci.yml")
+	cmd.Flags().StringSliceVar(&githubEnterprise, "github-enterprise", githubEnterprise, "--github-enterprise=host of a GitHub Enterprise Server, e.g. ghe.mycorp.com")
 	cmd.Flags().StringVar(&reg.Endpoint, "registry", reg.Endpoint, "--registry=URL or local file")
-	cmd.Flags().StringVar(&opts.Dest, "dest", opts.Dest, "--dest=empty for current working directory or %GOPATH%/author")
+	cmd.Flags().StringVar(&opts.Dest, "dest", opts.Dest, "--dest=empty for ./module-name (or $GOPATH/src/module with --gopath) or %GOPATH%/author")
+	cmd.Flags().BoolVar(&opts.GOPATH, "gopath", opts.GOPATH, "--gopath to install an empty --dest into $GOPATH/src/module, the legacy GOPATH mode")
 	cmd.Flags().StringVar(&opts.Module, "module", opts.Module, "--module=local module name")
+	cmd.Flags().StringVar(&opts.ModuleDir, "module-dir", opts.ModuleDir, "--module-dir=directory of the project's go.mod, e.g. backend, if it's not at the root")
+	cmd.Flags().StringVar(&opts.OldModule, "old-module", opts.OldModule, "--old-module=import path to rewrite to the module, instead of the go.mod's one")
+	cmd.Flags().StringSliceVar(&opts.Placeholders, "placeholder", opts.Placeholders, "--placeholder=import paths to rewrite to the module too, e.g. yourapp,example.com/changeme")
+	cmd.Flags().StringVar(&opts.AppName, "app-name", opts.AppName, "--app-name=myapp to rename the app inside the Dockerfile, Makefile and service files")
+	cmd.Flags().StringVar(&opts.AppNameToken, "app-name-token", opts.AppNameToken, "--app-name-token=app name to replace, defaults to the last element of the old module")
+	cmd.Flags().StringSliceVar(&opts.AppNameFiles, "app-name-files", opts.AppNameFiles, "--app-name-files=patterns of files to rename the app inside, e.g. Dockerfile,*.service")
+	cmd.Flags().StringToStringVar(&opts.Vars, "var", opts.Vars, "--var=AppName=myapp,Author=me to execute the project's .tmpl files")
+	cmd.Flags().StringVar((*string)(&opts.Overwrite), "overwrite", string(project.OverwriteFail), "--overwrite=fail, skip or force existing files")
+	cmd.Flags().Int64Var(&opts.MaxSize, "max-size", opts.MaxSize, "--max-size=limit of the extracted files in bytes, -1 for no limit")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", opts.Timeout, "--timeout=5m time limit of the download")
+	cmd.Flags().BoolVar(&opts.Offline, "offline", opts.Offline, "--offline to install from the cached archives only")
+	cmd.Flags().BoolVar(&opts.NoCache, "no-cache", opts.NoCache, "--no-cache to always download the project")
+	cmd.Flags().StringToStringVar(&opts.Replaces, "replace", opts.Replaces, "--replace=github.com/kataras/iris/v12=../iris to add replace directives to the go.mod")
+	cmd.Flags().BoolVar(&opts.Tidy, "tidy", opts.Tidy, "--tidy to run go mod tidy after installation")
+	cmd.Flags().BoolVar(&opts.Build, "build", opts.Build, "--build to verify that the project builds after installation")
+	cmd.Flags().BoolVar(&opts.Gitignore, "gitignore", opts.Gitignore, "--gitignore to write a .gitignore file if missing")
+	cmd.Flags().BoolVar(&opts.GitInit, "git", opts.GitInit, "--git to initialize a git repository after installation")
+	cmd.Flags().BoolVar(&opts.Format, "format", opts.Format, "--format to gofmt the rewritten go files")
+	cmd.Flags().BoolVar(&verbose, "verbose", verbose, "--verbose to log the installation steps")
+	cmd.Flags().BoolVar(&interactive, "interactive", interactive, "--interactive=false to never prompt for the missing options")
+	cmd.Flags().BoolVar(&yes, "yes", yes, "--yes to run the project's post install commands without confirmation")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", opts.DryRun, "--dry-run to list the files without writing them")
+	cmd.Flags().BoolVar(&rewrites, "rewrites", rewrites, "--rewrites to list the files which contain the old module and the number of its occurrences, implies --dry-run")
+	cmd.Flags().BoolVar(&printOnly, "print", printOnly, "--print to print the archive URL and the destination without installing")
+	cmd.Flags().StringVar(&opts.Checksum, "checksum", opts.Checksum, "--checksum=expected SHA256 of the project's archive")
 
 	return cmd
 }
 
+// askRepoOptions prompts for the version, the module and the destination of a project
+// which is installed directly from its repository, the current values are the defaults.
+func askRepoOptions(opts *project.Project) error {
+	qs := []*survey.Question{
+		{
+			Name:   "version",
+			Prompt: &survey.Input{Message: "Which version (branch, tag or commit) to install?", Default: opts.Version},
+		},
+		{
+			Name: "module",
+			Prompt: &survey.Input{Message: "What should be the new module name?", Default: opts.Module,
+				Help: "Leave it empty to be the same as the remote repository or type a different go module name for your project"},
+		},
+		{
+			Name:   "dest",
+			Prompt: &survey.Input{Message: "Choose directory to be installed:", Default: opts.Dest},
+		},
+	}
+
+	return survey.Ask(qs, opts)
+}
+
+// printResolved prints the archive URL, or the local source, and the destination of the project.
+func printResolved(cmd *cobra.Command, p *project.Project) error {
+	source, dest, err := p.Resolve()
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Source:\t%s\n", source)
+	cmd.Printf("Dest:\t%s\n", dest)
+	return nil
+}
+
+// printInstalled prints a summary of the installed project, if it's not a dry run.
+func printInstalled(cmd *cobra.Command, p *project.Project) {
+	if p.DryRun {
+		return
+	}
+
+	cmd.Printf("Project <%s> created with %d files.\n", p.Dest, len(p.Installed))
+
+	dirs, err := utils.FindMainPackageDirs(filepath.Join(p.Dest, filepath.FromSlash(p.ModuleDir)))
+	if err != nil || len(dirs) == 0 {
+		return
+	}
+
+	for i, dir := range dirs {
+		if dir != "." {
+			dirs[i] = "./" + dir
+		}
+	}
+
+	cmd.Printf("Run with: go run %s\n", dirs[0])
+	if len(dirs) > 1 {
+		cmd.Printf("Other main packages: %s\n", strings.Join(dirs[1:], ", "))
+	}
+}
+
+// downloadProgress returns a `Project.Progress` which renders a progress bar.
+func downloadProgress() func(current, total int64) {
+	var bar *pb.ProgressBar
+
+	return func(current, total int64) {
+		if bar == nil {
+			tmpl := `{{etime . "%s elapsed"}} {{speed . }}`
+			if total > 0 {
+				tmpl = `{{bar . }} {{percent . }} {{etime . "%s elapsed"}}`
+			} else {
+				// Content-Length is not available
+				// on Github release download response.
+				total = 0
+			}
+
+			bar = pb.ProgressBarTemplate(tmpl).Start64(total).SetMaxWidth(45)
+		}
+
+		bar.SetCurrent(current)
+
+		if current == total {
+			bar.SetTemplateString(`{{etime . "%s elapsed"}} [{{string . "all_bytes" | green}}]`)
+			bar.Set("all_bytes", formatByteLength(int(current)))
+			bar.Finish()
+			bar = nil
+		}
+	}
+}
+
 func formatByteLength(b int) string {
 	const unit = 1000
 	if b < unit {
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kataras/iris-cli/project"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+// iris-cli uninstall ./myproject
+// iris-cli uninstall --force ./myproject
+func uninstallCommand() *cobra.Command {
+	force := false
+
+	cmd := &cobra.Command{
+		Use:           "uninstall",
+		Short:         "Uninstall removes a project installed by the new command.",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("argument project directory is required")
+			}
+
+			p := &project.Project{Dest: args[0]}
+
+			if !force {
+				confirm := false
+				err := survey.AskOne(&survey.Confirm{Message: fmt.Sprintf("Remove the files installed into the %s directory?", args[0])}, &confirm)
+				if err != nil || !confirm {
+					return err
+				}
+			}
+
+			if err := p.Uninstall(); err != nil {
+				return err
+			}
+
+			cmd.Printf("Project <%s> removed.\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", force, "--force to remove without confirmation")
+
+	return cmd
+}
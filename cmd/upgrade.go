@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/kataras/iris-cli/project"
+
+	"github.com/spf13/cobra"
+)
+
+// iris-cli upgrade --version=v12.1.0
+// iris-cli upgrade --version=master ./myproject
+func upgradeCommand() *cobra.Command {
+	var (
+		version  string
+		checksum string
+	)
+
+	cmd := &cobra.Command{
+		Use:           "upgrade",
+		Short:         "Upgrade re-applies the project's repository, at a newer version, over an installed project.",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "./"
+			if len(args) > 0 {
+				dir = args[0]
+			}
+
+			p, err := project.ReadManifest(dir)
+			if err != nil {
+				return err
+			}
+
+			if version != "" {
+				p.Version = version
+			}
+			p.Checksum = checksum
+			p.Progress = downloadProgress()
+
+			upgraded, err := p.Upgrade()
+			for _, f := range upgraded {
+				switch f.Status {
+				case project.UpgradeConflict:
+					cmd.Printf("%s\t%s (locally modified, see %s.orig and %s.new)\n", f.Status, f.Path, f.Path, f.Path)
+				case project.UpgradeKept:
+					cmd.Printf("%s\t%s (locally modified, removed upstream)\n", f.Status, f.Path)
+				default:
+					cmd.Printf("%s\t%s\n", f.Status, f.Path)
+				}
+			}
+
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Project <%s> upgraded to version <%s>.\n", p.Dest, p.Version)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&version, "version", version, "--version=empty for the installed version, a branch, a tag or a commit SHA")
+	cmd.Flags().StringVar(&checksum, "checksum", checksum, "--checksum=expected SHA256 of the project's archive")
+
+	return cmd
+}
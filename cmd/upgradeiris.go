@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kataras/iris-cli/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// irisModule is the module path of the Iris Web Framework.
+const irisModule = "github.com/kataras/iris/v12"
+
+// iris-cli upgrade-iris
+// iris-cli upgrade-iris v12.1.8
+// iris-cli upgrade-iris --dir=./myproject --tidy=false master
+func upgradeIrisCommand() *cobra.Command {
+	var (
+		dir  = "./"
+		tidy = true
+	)
+
+	cmd := &cobra.Command{
+		Use:           "upgrade-iris",
+		Short:         "Upgrade-iris sets the Iris dependency of the project's go.mod to a version, defaults to the latest release.",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version := "latest"
+			if len(args) > 0 {
+				version = args[0]
+			}
+
+			if version == "latest" {
+				releases := utils.ListReleases("kataras/iris")
+				if len(releases) == 0 {
+					return fmt.Errorf("unable to find the latest release of Iris")
+				}
+				version = releases[0]
+			}
+
+			if strings.HasPrefix(version, "v") && !strings.HasPrefix(version, "v12.") {
+				return fmt.Errorf("version <%s> is not a version of the <%s> module", version, irisModule)
+			}
+
+			modFile := filepath.Join(utils.Dest(dir), "go.mod")
+			b, err := ioutil.ReadFile(modFile)
+			if err != nil {
+				return err
+			}
+
+			contents, old, err := utils.SetGoModRequire(b, irisModule, version)
+			if err != nil {
+				return fmt.Errorf("%s: %w", modFile, err)
+			}
+
+			if old == version {
+				cmd.Printf("Iris is already at version <%s>.\n", version)
+				return nil
+			}
+
+			if utils.IsCanonicalVersion(version) {
+				if err = ioutil.WriteFile(modFile, contents, 0644); err != nil {
+					return err
+				}
+			} else {
+				// A branch, a commit or a version query is not valid in a require line,
+				// the go command resolves it to a pseudo-version or to a canonical version.
+				if err = runGo(cmd, filepath.Dir(modFile), "get", irisModule+"@"+version); err != nil {
+					return err
+				}
+
+				if b, err = ioutil.ReadFile(modFile); err != nil {
+					return err
+				}
+
+				for _, require := range utils.GoModRequires(b) {
+					if strings.HasPrefix(require, irisModule+"@") {
+						version = strings.TrimPrefix(require, irisModule+"@")
+					}
+				}
+			}
+
+			if tidy {
+				// Updates the go.sum and removes the unused requirements.
+				if err = runGo(cmd, filepath.Dir(modFile), "mod", "tidy"); err != nil {
+					return err
+				}
+			}
+
+			cmd.Printf("Iris upgraded from <%s> to <%s>.\n", old, version)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", dir, "--dir=project directory which contains the go.mod file")
+	cmd.Flags().BoolVar(&tidy, "tidy", tidy, "--tidy=false to not run go mod tidy")
+
+	return cmd
+}
+
+// runGo runs the go command with "args" inside the "dir" directory, its output is written to the "cmd"'s one.
+func runGo(cmd *cobra.Command, dir string, args ...string) error {
+	goCmd := exec.Command("go", args...)
+	goCmd.Dir = dir
+	goCmd.Stdout = cmd.OutOrStdout()
+	goCmd.Stderr = cmd.ErrOrStderr()
+	return goCmd.Run()
+}
@@ -0,0 +1,81 @@
+package generate
+
+import (
+	"strings"
+	"text/template"
+)
+
+// Controller generates an Iris MVC controller file.
+type Controller struct {
+	// Name of the controller, e.g. "User" generates a "UserController" at "user_controller.go".
+	Name string
+	// Dest is the destination directory or file path, defaults to the current working directory.
+	Dest string
+	// Package is the go package declaration,
+	// if empty then it's resolved by the destination directory's files.
+	Package string
+	// Force overwrites an existing file.
+	Force bool
+}
+
+var controllerTmpl = template.Must(template.New("controller").Parse(`package {{.Package}}
+
+import (
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/mvc"
+)
+
+// Register{{.Type}} registers the {{.Type}} to the "{{.Path}}" path of the "app".
+func Register{{.Type}}(app *iris.Application) {
+	mvc.New(app.Party("{{.Path}}")).Handle(new({{.Type}}))
+}
+
+// {{.Type}} serves the "{{.Path}}" resource.
+type {{.Type}} struct {
+	Ctx iris.Context
+}
+
+// Get handles GET: {{.Path}}.
+func (c *{{.Type}}) Get() mvc.Result {
+	return mvc.Response{Text: "GET {{.Path}}"}
+}
+
+// GetBy handles GET: {{.Path}}/{id}.
+func (c *{{.Type}}) GetBy(id int64) mvc.Result {
+	return mvc.Response{Object: iris.Map{"id": id}}
+}
+
+// Post handles POST: {{.Path}}.
+func (c *{{.Type}}) Post() mvc.Result {
+	return mvc.Response{Code: iris.StatusCreated}
+}
+
+// PutBy handles PUT: {{.Path}}/{id}.
+func (c *{{.Type}}) PutBy(id int64) mvc.Result {
+	return mvc.Response{Object: iris.Map{"id": id}}
+}
+
+// DeleteBy handles DELETE: {{.Path}}/{id}.
+func (c *{{.Type}}) DeleteBy(id int64) mvc.Result {
+	return mvc.Response{Code: iris.StatusNoContent}
+}
+`))
+
+// Generate writes the controller file and returns its path.
+func (c *Controller) Generate() (string, error) {
+	name, err := exportedName(strings.TrimSuffix(c.Name, "Controller"))
+	if err != nil {
+		return "", err
+	}
+
+	w := words(name)
+	fpath := resolvePath(c.Dest, strings.Join(w, "_")+"_controller.go")
+
+	data := map[string]string{
+		"Package": resolvePackage(fpath, c.Package),
+		"Type":    name + "Controller",
+		"Path":    "/" + strings.Join(w, "-"),
+	}
+
+	return fpath, writeSource(fpath, controllerTmpl, data, c.Force)
+}
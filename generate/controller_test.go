@@ -0,0 +1,60 @@
+package generate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestControllerGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err = ioutil.WriteFile(filepath.Join(dir, "routes.go"), []byte("package api\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := Controller{Name: "UserProfile", Dest: dir}
+	fpath, err := c.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := filepath.Join(dir, "user_profile_controller.go"); fpath != expected {
+		t.Fatalf("expected path: %s but got: %s", expected, fpath)
+	}
+
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"package api\n",
+		"type UserProfileController struct",
+		`mvc.New(app.Party("/user-profile")).Handle(new(UserProfileController))`,
+		"func (c *UserProfileController) GetBy(id int64) mvc.Result",
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Fatalf("expected file to contain: %q but got:\n%s", expected, b)
+		}
+	}
+
+	if _, err = c.Generate(); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected an already exists error but got: %v", err)
+	}
+
+	c.Force = true
+	if _, err = c.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = (&Controller{Name: "not-valid", Dest: dir}).Generate(); err == nil {
+		t.Fatal("expected an invalid name error")
+	}
+}
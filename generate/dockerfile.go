@@ -0,0 +1,87 @@
+package generate
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"text/template"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// Dockerfile generates a multi-stage Dockerfile for an Iris application:
+// a build stage which compiles the module and a minimal runtime image.
+type Dockerfile struct {
+	// Dir is the project's directory, which contains the go.mod file,
+	// defaults to the current working directory.
+	Dir string
+	// GoVersion is the version of the golang image of the build stage,
+	// defaults to the go directive of the project's go.mod or, if it's missing, to "1.14".
+	GoVersion string
+	// Port is the port which the application listens on, defaults to 8080.
+	Port int
+	// Force overwrites an existing Dockerfile.
+	Force bool
+}
+
+var dockerfileTmpl = template.Must(template.New("dockerfile").Parse(`# Build stage.
+FROM golang:{{.GoVersion}}-alpine AS builder
+WORKDIR /src
+COPY go.mod go.sum* ./
+RUN go mod download
+COPY . .
+RUN CGO_ENABLED=0 go build -ldflags="-s -w" -o /bin/{{.Binary}} {{.BuildPath}}
+
+# Runtime stage.
+FROM alpine:3
+RUN apk add --no-cache ca-certificates
+WORKDIR /app
+COPY --from=builder /bin/{{.Binary}} ./{{.Binary}}
+# Copy the files which are loaded at runtime, if any, e.g.
+# COPY --from=builder /src/views ./views
+EXPOSE {{.Port}}
+ENTRYPOINT ["./{{.Binary}}"]
+`))
+
+// Generate writes the Dockerfile and returns its path.
+// The binary is named after the module of the project's go.mod and it's built
+// from the main package which is closest to the project's directory, e.g. "." or "./cmd/app".
+func (d *Dockerfile) Generate() (string, error) {
+	dir := utils.Dest(d.Dir)
+	module, err := readModule(dir)
+	if err != nil {
+		return "", err
+	}
+
+	goVersion := d.GoVersion
+	if goVersion == "" {
+		if b, err := ioutil.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			goVersion = utils.GoVersion(b)
+		}
+
+		if goVersion == "" {
+			goVersion = "1.14"
+		}
+	}
+
+	buildPath := "."
+	if dirs, err := utils.FindMainPackageDirs(dir); err != nil {
+		return "", err
+	} else if len(dirs) > 0 && dirs[0] != "." {
+		buildPath = "./" + dirs[0]
+	}
+
+	port := d.Port
+	if port <= 0 {
+		port = 8080
+	}
+
+	fpath := filepath.Join(dir, "Dockerfile")
+	data := map[string]interface{}{
+		"Binary":    utils.ModuleName(module),
+		"BuildPath": buildPath,
+		"GoVersion": goVersion,
+		"Port":      port,
+	}
+
+	return fpath, writeFile(fpath, dockerfileTmpl, data, d.Force, nil, 0644)
+}
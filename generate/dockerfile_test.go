@@ -0,0 +1,101 @@
+package generate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDockerfileGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	d := Dockerfile{Dir: dir, Port: 3000}
+	if _, err = d.Generate(); err == nil || !strings.Contains(err.Error(), "go.mod is missing") {
+		t.Fatalf("expected a missing go.mod error but got: %v", err)
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/author/app/v2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fpath, err := d.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := filepath.Join(dir, "Dockerfile"); fpath != expected {
+		t.Fatalf("expected path: %s but got: %s", expected, fpath)
+	}
+
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"FROM golang:1.14-alpine AS builder\n",
+		"go build -ldflags=\"-s -w\" -o /bin/app .\n",
+		"EXPOSE 3000\n",
+		"ENTRYPOINT [\"./app\"]\n",
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Fatalf("expected file to contain: %q but got:\n%s", expected, b)
+		}
+	}
+
+	if _, err = d.Generate(); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected an already exists error but got: %v", err)
+	}
+
+	d.Force = true
+	if _, err = d.Generate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDockerfileGenerateGoVersionAndMainPackage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":          "module github.com/author/app\n\ngo 1.21\n\ntoolchain go1.21.5\n",
+		"cmd/app/main.go": "package main\n\nfunc main() {}\n",
+	}
+	for name, contents := range files {
+		fpath := filepath.Join(dir, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(fpath, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fpath, err := (&Dockerfile{Dir: dir}).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"FROM golang:1.21-alpine AS builder\n",
+		"go build -ldflags=\"-s -w\" -o /bin/app ./cmd/app\n",
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Fatalf("expected file to contain: %q but got:\n%s", expected, b)
+		}
+	}
+}
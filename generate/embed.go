@@ -0,0 +1,137 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// Embed generates an "embed.go" file which bundles an assets directory to the binary
+// with a `//go:embed` directive and serves it through Iris' file server. It requires go 1.16+.
+type Embed struct {
+	// Dir is the assets directory, e.g. "./public".
+	// It should be inside the destination directory, as the go:embed patterns can not contain "..".
+	Dir string
+	// Route is the request path prefix which the assets are served on, defaults to "/" + the directory's name.
+	Route string
+	// Dest is the destination directory or file path, defaults to the current working directory.
+	Dest string
+	// Package is the go package declaration,
+	// if empty then it's resolved by the destination directory's files.
+	Package string
+	// Force overwrites an existing file.
+	Force bool
+}
+
+var embedTmpl = template.Must(template.New("embed").Parse(`package {{.Package}}
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/kataras/iris/v12"
+)
+
+//go:embed {{.Pattern}}
+var {{.Var}} embed.FS
+
+// Register{{.Name}} serves the embedded {{.Pattern}} directory's files on the "{{.Route}}" route of the "app".
+func Register{{.Name}}(app *iris.Application) {
+	dir, err := fs.Sub({{.Var}}, {{printf "%q" .Dir}})
+	if err != nil {
+		panic(err)
+	}
+
+	app.HandleDir("{{.Route}}", http.FS(dir))
+}
+`))
+
+// Generate writes the embed file and returns its path.
+func (e *Embed) Generate() (string, error) {
+	if e.Dir == "" {
+		return "", fmt.Errorf("assets directory is required")
+	}
+
+	fpath := resolvePath(e.Dest, "embed.go")
+	dir, err := filepath.Abs(e.Dir)
+	if err != nil {
+		return "", err
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("assets directory <%s> does not exist", e.Dir)
+	}
+
+	rel, err := filepath.Rel(filepath.Dir(fpath), dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("assets directory <%s> should be a subdirectory of <%s>", e.Dir, filepath.Dir(fpath))
+	}
+	rel = filepath.ToSlash(rel)
+
+	name := identifier(path.Base(rel))
+	if name == "" {
+		return "", fmt.Errorf("assets directory <%s> has no valid identifier characters", e.Dir)
+	}
+
+	pattern := rel
+	if strings.ContainsAny(pattern, " \t\"") {
+		pattern = strconv.Quote(pattern)
+	}
+
+	route := e.Route
+	if route == "" {
+		route = path.Base(rel)
+	}
+	if !strings.HasPrefix(route, "/") {
+		route = "/" + route
+	}
+
+	data := map[string]string{
+		"Package": resolvePackage(fpath, e.Package),
+		"Name":    name,
+		"Var":     unexportedName(name) + "FS",
+		"Pattern": pattern,
+		"Dir":     rel,
+		"Route":   route,
+	}
+
+	return fpath, writeSource(fpath, embedTmpl, data, e.Force)
+}
+
+// unexportedName returns the "name" with its first letter lowercased, e.g. "Public" to "public".
+func unexportedName(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+// identifier returns the "name" as an exported camel case Go identifier,
+// the characters which are not letters or digits separate its words, e.g. "static-files" to "StaticFiles".
+func identifier(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			continue // identifiers can not start with a digit.
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package generate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmbedGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err = ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	assets := filepath.Join(dir, "static-files")
+	if err = os.Mkdir(assets, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	e := Embed{Dir: assets, Dest: dir}
+	fpath, err := e.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := filepath.Join(dir, "embed.go"); fpath != expected {
+		t.Fatalf("expected path: %s but got: %s", expected, fpath)
+	}
+
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"package main\n",
+		"//go:embed static-files\nvar staticFilesFS embed.FS\n",
+		"func RegisterStaticFiles(app *iris.Application) {",
+		`fs.Sub(staticFilesFS, "static-files")`,
+		`app.HandleDir("/static-files", http.FS(dir))`,
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Fatalf("expected file to contain: %q but got:\n%s", expected, b)
+		}
+	}
+
+	if _, err = e.Generate(); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected an already exists error but got: %v", err)
+	}
+
+	// The assets should be inside the package's directory.
+	if _, err = (&Embed{Dir: dir, Dest: assets}).Generate(); err == nil || !strings.Contains(err.Error(), "subdirectory") {
+		t.Fatalf("expected a subdirectory error but got: %v", err)
+	}
+
+	if _, err = (&Embed{Dir: filepath.Join(dir, "missing"), Dest: dir}).Generate(); err == nil {
+		t.Fatal("expected a missing directory error")
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+	}{
+		{"public", "Public"},
+		{"static-files", "StaticFiles"},
+		{"web_assets.v2", "WebAssetsV2"},
+		{"3d", "D"},
+		{"---", ""},
+	}
+
+	for i, tt := range tests {
+		if got := identifier(tt.name); tt.expected != got {
+			t.Fatalf("[%d] expected identifier of <%s>: %s but got: %s", i, tt.name, tt.expected, got)
+		}
+	}
+}
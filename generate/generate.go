@@ -0,0 +1,118 @@
+// Package generate contains generators of Go source files for Iris applications,
+// e.g. controllers and handlers, written in the package of the target directory,
+// and of project files, e.g. a Dockerfile, based on the project's go.mod.
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// resolvePackage returns the "pkg" if not empty, otherwise the package of the
+// "fpath" file's directory. Defaults to "main".
+func resolvePackage(fpath, pkg string) string {
+	if pkg != "" {
+		return pkg
+	}
+
+	if p := utils.TryFindPackage(fpath); len(p) > 0 {
+		return string(p)
+	}
+
+	return "main"
+}
+
+// resolvePath returns the "dest" if it's a file path,
+// otherwise the "filename" inside the "dest" directory.
+func resolvePath(dest, filename string) string {
+	fpath := utils.Dest(dest)
+	if utils.Ext(fpath) == "" {
+		fpath = filepath.Join(fpath, filename)
+	}
+
+	return fpath
+}
+
+// writeSource executes the "tmpl" with "data", formats the result and saves it to "fpath".
+// It fails if the file already exists, unless "force" is true.
+func writeSource(fpath string, tmpl *template.Template, data interface{}, force bool) error {
+	return writeFile(fpath, tmpl, data, force, format.Source, 0644)
+}
+
+// writeFile executes the "tmpl" with "data", passes the result through the optional "transform"
+// and saves it to "fpath" with the "perm" mode. It fails if the file already exists, unless "force" is true.
+func writeFile(fpath string, tmpl *template.Template, data interface{}, force bool, transform func([]byte) ([]byte, error), perm os.FileMode) error {
+	if !force && utils.Exists(fpath) {
+		return fmt.Errorf("file <%s> already exists", fpath)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	b := buf.Bytes()
+	if transform != nil {
+		var err error
+		if b, err = transform(b); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(fpath, b, perm)
+}
+
+// readModule returns the module path of the go.mod file inside the "dir" directory.
+func readModule(dir string) (string, error) {
+	fpath := filepath.Join(dir, "go.mod")
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("directory <%s> is not a go module: go.mod is missing", dir)
+		}
+		return "", err
+	}
+
+	module := string(utils.ModulePath(b))
+	if module == "" {
+		return "", fmt.Errorf("file <%s> has no module declaration", fpath)
+	}
+
+	return module, nil
+}
+
+// exportedName returns the "name" as an exported Go identifier, e.g. "user" to "User".
+func exportedName(name string) (string, error) {
+	if !token.IsIdentifier(name) {
+		return "", fmt.Errorf("name <%s> is not a valid identifier", name)
+	}
+
+	return strings.ToUpper(name[:1]) + name[1:], nil
+}
+
+// words splits a camel case "name" to its lowercase words, e.g. "UserProfile" to ["user", "profile"].
+func words(name string) (w []string) {
+	start := 0
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) && !unicode.IsUpper(rune(name[i-1])) {
+			w = append(w, strings.ToLower(name[start:i]))
+			start = i
+		}
+	}
+
+	return append(w, strings.ToLower(name[start:]))
+}
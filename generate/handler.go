@@ -0,0 +1,90 @@
+package generate
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+)
+
+// Handler generates an Iris route handler file.
+type Handler struct {
+	// Name of the handler, e.g. "Ping" generates a "Ping" function at "ping.go".
+	Name string
+	// Method is the HTTP method of the route, defaults to "GET".
+	Method string
+	// Path is the route's path, defaults to the lowercase name, e.g. "/ping".
+	Path string
+	// Dest is the destination directory or file path, defaults to the current working directory.
+	Dest string
+	// Package is the go package declaration,
+	// if empty then it's resolved by the destination directory's files.
+	Package string
+	// Force overwrites an existing file.
+	Force bool
+}
+
+var handlerTmpl = template.Must(template.New("handler").Parse(`package {{.Package}}
+
+import "github.com/kataras/iris/v12"
+
+// Register{{.Name}} registers the {{.Name}} handler to the "{{.Method}} {{.Path}}" route of the "app".
+func Register{{.Name}}(app *iris.Application) {
+	app.Handle("{{.Method}}", "{{.Path}}", {{.Name}})
+}
+
+// {{.Name}} handles {{.Method}}: {{.Path}}.
+func {{.Name}}(ctx iris.Context) {
+	ctx.JSON(iris.Map{"message": "{{.Name}}"})
+}
+`))
+
+var methods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+}
+
+// Generate writes the handler file and returns its path.
+func (h *Handler) Generate() (string, error) {
+	name, err := exportedName(h.Name)
+	if err != nil {
+		return "", err
+	}
+
+	method := strings.ToUpper(h.Method)
+	if method == "" {
+		method = http.MethodGet
+	} else if !isMethod(method) {
+		return "", fmt.Errorf("method <%s> is not a valid HTTP method", h.Method)
+	}
+
+	w := words(name)
+	routePath := h.Path
+	if routePath == "" {
+		routePath = strings.Join(w, "-")
+	}
+	if !strings.HasPrefix(routePath, "/") {
+		routePath = "/" + routePath
+	}
+
+	fpath := resolvePath(h.Dest, strings.Join(w, "_")+".go")
+
+	data := map[string]string{
+		"Package": resolvePackage(fpath, h.Package),
+		"Name":    name,
+		"Method":  method,
+		"Path":    routePath,
+	}
+
+	return fpath, writeSource(fpath, handlerTmpl, data, h.Force)
+}
+
+func isMethod(method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
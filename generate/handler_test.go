@@ -0,0 +1,54 @@
+package generate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandlerGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err = ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := Handler{Name: "ping", Method: "post", Path: "api/ping", Dest: dir}
+	fpath, err := h.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := filepath.Join(dir, "ping.go"); fpath != expected {
+		t.Fatalf("expected path: %s but got: %s", expected, fpath)
+	}
+
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"package main\n",
+		`app.Handle("POST", "/api/ping", Ping)`,
+		"func Ping(ctx iris.Context) {",
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Fatalf("expected file to contain: %q but got:\n%s", expected, b)
+		}
+	}
+
+	if _, err = h.Generate(); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected an already exists error but got: %v", err)
+	}
+
+	if _, err = (&Handler{Name: "Other", Method: "FETCH", Dest: dir}).Generate(); err == nil {
+		t.Fatal("expected an invalid method error")
+	}
+}
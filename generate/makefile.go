@@ -0,0 +1,67 @@
+package generate
+
+import (
+	"path/filepath"
+	"text/template"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// Makefile generates a Makefile with build, run, test and tidy targets for an Iris application.
+type Makefile struct {
+	// Dir is the project's directory, which contains the go.mod file,
+	// defaults to the current working directory.
+	Dir string
+	// Binary is the name of the executable, if empty then it's the last element
+	// of the module path, without the major version suffix, e.g. "app" for "github.com/author/app/v2".
+	Binary string
+	// Port is the default value of the PORT variable, which is passed to the application on run, defaults to 8080.
+	Port int
+	// Force overwrites an existing Makefile.
+	Force bool
+}
+
+var makefileTmpl = template.Must(template.New("makefile").Parse(`BINARY ?= {{.Binary}}
+PORT ?= {{.Port}}
+
+.PHONY: build run test tidy
+
+build:
+	go build -o bin/$(BINARY) .
+
+run: build
+	PORT=$(PORT) ./bin/$(BINARY)
+
+test:
+	go test -v ./...
+
+tidy:
+	go mod tidy
+`))
+
+// Generate writes the Makefile and returns its path.
+func (m *Makefile) Generate() (string, error) {
+	dir := utils.Dest(m.Dir)
+	module, err := readModule(dir)
+	if err != nil {
+		return "", err
+	}
+
+	binary := m.Binary
+	if binary == "" {
+		binary = utils.ModuleName(module)
+	}
+
+	port := m.Port
+	if port <= 0 {
+		port = 8080
+	}
+
+	fpath := filepath.Join(dir, "Makefile")
+	data := map[string]interface{}{
+		"Binary": binary,
+		"Port":   port,
+	}
+
+	return fpath, writeFile(fpath, makefileTmpl, data, m.Force, nil, 0644)
+}
@@ -0,0 +1,67 @@
+package generate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMakefileGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err = ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/author/app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := Makefile{Dir: dir}
+	fpath, err := m.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := filepath.Join(dir, "Makefile"); fpath != expected {
+		t.Fatalf("expected path: %s but got: %s", expected, fpath)
+	}
+
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"BINARY ?= app\n",
+		"PORT ?= 8080\n",
+		"build:\n\tgo build -o bin/$(BINARY) .\n",
+		"run: build\n",
+		"test:\n\tgo test -v ./...\n",
+		"tidy:\n\tgo mod tidy\n",
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Fatalf("expected file to contain: %q but got:\n%s", expected, b)
+		}
+	}
+
+	if _, err = m.Generate(); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected an already exists error but got: %v", err)
+	}
+
+	m = Makefile{Dir: dir, Binary: "server", Port: 3000, Force: true}
+	if _, err = m.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(b), "BINARY ?= server\nPORT ?= 3000\n") {
+		t.Fatalf("expected the binary and the port to be set but got:\n%s", b)
+	}
+}
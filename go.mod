@@ -6,7 +6,9 @@ require (
 	github.com/AlecAivazis/survey/v2 v2.0.5
 	github.com/BurntSushi/toml v0.3.1
 	github.com/cheggaaa/pb/v3 v3.0.3
+	github.com/mattn/go-isatty v0.0.10
 	github.com/spf13/cobra v0.0.5
+	github.com/yeka/zip v0.0.0-20231116150916-03d6312748a9
 	gopkg.in/yaml.v2 v2.2.2
 )
 
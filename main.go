@@ -23,6 +23,6 @@ func main() {
 	app := cmd.New(buildRevision, buildTime)
 	if err := app.Execute(); err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
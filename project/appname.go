@@ -0,0 +1,119 @@
+package project
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// DefaultAppNameFiles are the patterns of the files which the `Project.AppName` is replaced inside,
+// when `Project.AppNameFiles` is empty.
+var DefaultAppNameFiles = []string{
+	"Dockerfile",
+	"Dockerfile.*",
+	"*.dockerfile",
+	"docker-compose.yml",
+	"docker-compose.yaml",
+	"Makefile",
+	"*.mk",
+	"Procfile",
+	"*.service",
+	".goreleaser.yml",
+	".goreleaser.yaml",
+}
+
+// appNameFiles returns the `AppNameFiles` or the `DefaultAppNameFiles`.
+func (p *Project) appNameFiles() []string {
+	if len(p.AppNameFiles) > 0 {
+		return p.AppNameFiles
+	}
+
+	return DefaultAppNameFiles
+}
+
+// appNameToken returns the token which is replaced with the `AppName`: the `AppNameToken`,
+// the "configured" one of the project's `ConfigFilename` or the last path element of the "oldModule".
+// It returns nil if there is nothing to replace.
+func (p *Project) appNameToken(configured, oldModule string) []byte {
+	if p.AppName == "" {
+		return nil
+	}
+
+	token := p.AppNameToken
+	if token == "" {
+		token = configured
+	}
+	if token == "" {
+		token = utils.ModuleName(oldModule)
+	}
+
+	if token == "" || token == "." || token == p.AppName {
+		return nil
+	}
+
+	return []byte(token)
+}
+
+// isAppNameFile reports whether the `AppName` should be replaced inside the "name" file.
+// The module files are never matched, they are rewritten by the module rename instead.
+func (p *Project) isAppNameFile(name string) bool {
+	return !isModuleFile(name) && matchAny(p.appNameFiles(), name)
+}
+
+// replaceAppName replaces the whole words "token" of the "contents" with the "appName".
+// Binary contents are kept as they are.
+func replaceAppName(contents, token, appName []byte) []byte {
+	if len(token) == 0 || utils.IsBinary(contents) {
+		return contents
+	}
+
+	var (
+		buf  bytes.Buffer
+		last int
+	)
+
+	for i := 0; ; {
+		j := bytes.Index(contents[i:], token)
+		if j < 0 {
+			break
+		}
+
+		start, end := i+j, i+j+len(token)
+		if isWordBoundary(contents, start, end) {
+			buf.Write(contents[last:start])
+			buf.Write(appName)
+			last = end
+		}
+		i = start + 1
+	}
+
+	if last == 0 {
+		return contents
+	}
+
+	buf.Write(contents[last:])
+	return buf.Bytes()
+}
+
+// isWordBoundary reports whether the "contents[start:end]" is not surrounded by word characters.
+func isWordBoundary(contents []byte, start, end int) bool {
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRune(contents[:start]); isWordChar(r) {
+			return false
+		}
+	}
+
+	if end < len(contents) {
+		if r, _ := utf8.DecodeRune(contents[end:]); isWordChar(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isWordChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,123 @@
+package project
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// resolveDefaultBranch sets the `Version` to the default branch of the repository, on `DefaultBranch`,
+// if it's the default version. If the provider does not support it or its API is unavailable
+// then the version is kept and the "master" to "main" fallback of `download` applies.
+func (p *Project) resolveDefaultBranch(ctx context.Context) {
+	if !p.DefaultBranch || p.Version != defaultVersion {
+		return
+	}
+
+	branch, err := p.defaultBranch(ctx)
+	if err != nil {
+		p.logf("default branch of <%s> is unknown: %v", p.Repo, err)
+		return
+	}
+
+	p.logf("default branch of <%s> resolved to <%s>", p.Repo, branch)
+	p.Version = branch
+}
+
+// defaultBranch returns the default branch of the project's repository, as reported by its provider's API,
+// e.g. "main" or "develop". The result is cached like the archives, see `CacheTTL` and `Offline`.
+func (p *Project) defaultBranch(ctx context.Context) (string, error) {
+	provider, repo := ProviderOf(p.Repo)
+	if provider.DefaultBranchURL == nil {
+		return "", fmt.Errorf("the default branch lookup is not supported by <%s>", provider.Host)
+	}
+
+	cacheFile := p.branchCacheFile()
+	if branch, ok := p.cachedBranch(cacheFile); ok {
+		return branch, nil
+	}
+
+	if p.Offline {
+		return "", fmt.Errorf("repository <%s> default branch is %w", p.Repo, ErrNotCached)
+	}
+
+	b, err := utils.DownloadContext(ctx, p.httpClient(), provider.DefaultBranchURL(repo), nil, p.downloadOptions(provider)...)
+	if err != nil {
+		return "", p.downloadError(err)
+	}
+
+	var resp struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err = json.Unmarshal(b, &resp); err != nil {
+		return "", err
+	}
+
+	if resp.DefaultBranch == "" {
+		return "", fmt.Errorf("repository <%s> has no default branch", p.Repo)
+	}
+
+	if cacheFile != "" {
+		ioutil.WriteFile(cacheFile, []byte(resp.DefaultBranch), 0644)
+	}
+
+	return resp.DefaultBranch, nil
+}
+
+// branchCacheFile returns the path of the cached default branch of the project's repository,
+// the file may not exist. It returns an empty path if `NoCache` is true or there is no cache directory.
+func (p *Project) branchCacheFile() string {
+	if p.NoCache {
+		return ""
+	}
+
+	root, err := userCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	dir := filepath.Join(root, "iris-cli", "branches")
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return ""
+	}
+
+	key := sha256.Sum256([]byte(p.Repo))
+	return filepath.Join(dir, hex.EncodeToString(key[:]))
+}
+
+// cachedBranch returns the branch of the "cacheFile", if it exists and it's not expired.
+func (p *Project) cachedBranch(cacheFile string) (string, bool) {
+	if cacheFile == "" {
+		return "", false
+	}
+
+	info, err := os.Stat(cacheFile)
+	if err != nil {
+		return "", false
+	}
+
+	ttl := p.CacheTTL
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	if !p.Offline && time.Since(info.ModTime()) > ttl {
+		return "", false
+	}
+
+	b, err := ioutil.ReadFile(cacheFile)
+	if branch := strings.TrimSpace(string(b)); err == nil && branch != "" {
+		return branch, true
+	}
+
+	return "", false
+}
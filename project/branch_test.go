@@ -0,0 +1,93 @@
+package project
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectInstallDefaultBranch(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"project-develop/main.go", "package main\n"},
+		testFile{"project-develop/go.mod", "module github.com/author/project\n"},
+	)
+
+	var (
+		lookups   int
+		available = true
+	)
+	repo, closeProvider := newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/author/project":
+			lookups++
+			if !available {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"name":"project","default_branch":"develop"}`))
+		case "/author/project/develop.zip":
+			w.Write(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer closeProvider()
+
+	provider, _ := ProviderOf(repo)
+	provider.DefaultBranchURL = func(repo string) string {
+		return "http://" + provider.Host + "/api/" + repo
+	}
+
+	install := func(p *Project) (*Project, error) {
+		dest := newTestDest(t)
+		defer os.RemoveAll(dest)
+
+		p.Dest = dest
+		p.Retries = -1
+		installed, err := p.Installation(context.Background())
+		if err == nil {
+			expectFile(t, filepath.Join(dest, "main.go"), "package main\n")
+		}
+		return installed, err
+	}
+
+	p := New("project", repo)
+	p.DefaultBranch = true
+	installed, err := install(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "develop", installed.Version; expected != got {
+		t.Fatalf("expected version: %s but got: %s", expected, got)
+	}
+
+	// The lookup is cached.
+	if _, err = install(p); err != nil {
+		t.Fatal(err)
+	}
+	if lookups != 1 {
+		t.Fatalf("expected 1 default branch lookup but got %d", lookups)
+	}
+
+	// Without the API the master and main branches are tried.
+	available = false
+	p.NoCache = true
+	if _, err = install(p); err == nil {
+		t.Fatalf("expected an error when neither master nor main exist")
+	}
+	if lookups != 2 {
+		t.Fatalf("expected 2 default branch lookups but got %d", lookups)
+	}
+
+	// An explicit version is not looked up.
+	p.Version = "develop"
+	if _, err = install(p); err != nil {
+		t.Fatal(err)
+	}
+	if lookups != 2 {
+		t.Fatalf("expected no default branch lookup for an explicit version but got %d", lookups)
+	}
+}
@@ -0,0 +1,84 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// BuildError is caused by `Build` when the project does not build, use `errors.As` to check for it.
+type BuildError struct {
+	// Dir is the directory which the build ran inside.
+	Dir string
+	// Output is the output of the "go build" command.
+	Output string
+	// MissingDependencies reports whether the build failed because of dependencies which are not downloaded
+	// or not listed in the go.mod and go.sum files, instead of compile errors.
+	MissingDependencies bool
+	// Err is the error of the command, e.g. its exit status.
+	Err error
+}
+
+func (e *BuildError) Error() string {
+	if e.MissingDependencies {
+		return fmt.Sprintf("project <%s> has missing dependencies, please run \"go mod download\" or \"go mod tidy\":\n%s", e.Dir, e.Output)
+	}
+
+	return fmt.Sprintf("project <%s> does not compile:\n%s", e.Dir, e.Output)
+}
+
+func (e *BuildError) Unwrap() error {
+	return e.Err
+}
+
+// missingDependencyMessages are parts of the go command's errors when a dependency can't be resolved.
+var missingDependencyMessages = []string{
+	"no required module provides package",
+	"missing go.sum entry",
+	"cannot find module providing package",
+	"updates to go.mod needed",
+	"module lookup disabled",
+	"is not in GOROOT",
+	"cannot find package",
+}
+
+// Build runs "go build ./..." inside the "dir" directory, e.g. of an installed project's go.mod,
+// to verify that it compiles. The binaries of the main packages are discarded. On failure it returns a `*BuildError`.
+func Build(ctx context.Context, dir string) error {
+	// Write the binaries of the main packages to a temporary directory, instead of the project's one.
+	binDir, err := ioutil.TempDir("", "iris-cli-build")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(binDir)
+
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", binDir+string(os.PathSeparator), "./...")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if _, ok := err.(*exec.Error); ok {
+		return fmt.Errorf("go build: %w", err) // e.g. go is not installed.
+	}
+
+	output := string(bytes.TrimSpace(out))
+	buildErr := &BuildError{Dir: dir, Output: output, Err: err}
+	for _, msg := range missingDependencyMessages {
+		if strings.Contains(output, msg) {
+			buildErr.MissingDependencies = true
+			break
+		}
+	}
+
+	return buildErr
+}
@@ -0,0 +1,61 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	// Never resolve the missing modules through the network.
+	for key, value := range map[string]string{"GOFLAGS": "-mod=readonly", "GOPROXY": "off"} {
+		defer os.Setenv(key, os.Getenv(key))
+		os.Setenv(key, value)
+	}
+
+	tests := []struct {
+		name                string
+		main                string
+		ok                  bool
+		missingDependencies bool
+	}{
+		{"healthy", "package main\n\nfunc main() {}\n", true, false},
+		{"compile error", "package main\n\nfunc main() { undefined() }\n", false, false},
+		{"missing dependency", "package main\n\nimport _ \"github.com/author/missing\"\n\nfunc main() {}\n", false, true},
+	}
+
+	for _, tt := range tests {
+		dir := newTestDest(t)
+		defer os.RemoveAll(dir)
+
+		for name, contents := range map[string]string{"go.mod": "module github.com/author/project\n\ngo 1.13\n", "main.go": tt.main} {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err := Build(context.Background(), dir)
+		if tt.ok {
+			if err != nil {
+				t.Fatalf("[%s] %v", tt.name, err)
+			}
+		} else {
+			var buildErr *BuildError
+			if !errors.As(err, &buildErr) {
+				t.Fatalf("[%s] expected a build error but got: %v", tt.name, err)
+			}
+
+			if buildErr.MissingDependencies != tt.missingDependencies {
+				t.Fatalf("[%s] expected missing dependencies: %v but got: %v\n%s", tt.name, tt.missingDependencies, buildErr.MissingDependencies, buildErr.Output)
+			}
+		}
+
+		// The binary is not written inside the project.
+		if files, _ := ioutil.ReadDir(dir); len(files) != 2 {
+			t.Fatalf("[%s] expected only the project's files but got %d files", tt.name, len(files))
+		}
+	}
+}
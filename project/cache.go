@@ -0,0 +1,136 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// DefaultCacheTTL is the duration which a cached archive is used for when `Project.CacheTTL` is zero.
+const DefaultCacheTTL = 24 * time.Hour
+
+// userCacheDir returns the root directory of the cached archives, e.g. $HOME/.cache.
+var userCacheDir = os.UserCacheDir
+
+// cacheFile returns the path of the project's cached archive,
+// the file may not exist. It returns an empty path if `NoCache` is true.
+func (p *Project) cacheFile() (string, error) {
+	if p.NoCache {
+		return "", nil
+	}
+
+	root, err := userCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(root, "iris-cli", "archives")
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	source := p.Repo
+	if p.GoProxy {
+		// The module zips differ from the repository archives of the same version.
+		source = "goproxy/" + source
+	}
+
+	key := sha256.Sum256([]byte(source + "@" + p.Version))
+	return filepath.Join(dir, hex.EncodeToString(key[:])+".zip"), nil
+}
+
+// cached reports whether the "cacheFile" exists, it's not expired
+// and it matches the `Checksum`, if any.
+func (p *Project) cached(cacheFile string) (bool, error) {
+	if cacheFile == "" {
+		return false, nil
+	}
+
+	info, err := os.Stat(cacheFile)
+	if err != nil {
+		return false, nil
+	}
+
+	ttl := p.CacheTTL
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	if !p.Offline && time.Since(info.ModTime()) > ttl {
+		return false, nil
+	}
+
+	if p.Checksum == "" {
+		return true, nil
+	}
+
+	checksum, err := fileChecksum(cacheFile)
+	if err != nil {
+		return false, err
+	}
+
+	if err = p.verify(checksum); err != nil && p.Offline {
+		return false, err
+	}
+
+	// On mismatch download it again, the expected checksum may be changed.
+	return err == nil, nil
+}
+
+// etagFile returns the path of the file which keeps the ETag of the "cacheFile" response.
+func etagFile(cacheFile string) string {
+	return cacheFile + ".etag"
+}
+
+// saveETag saves the "etag" of the "cacheFile", an empty one removes the previous one.
+func saveETag(cacheFile, etag string) {
+	if etag == "" {
+		os.Remove(etagFile(cacheFile))
+		return
+	}
+
+	ioutil.WriteFile(etagFile(cacheFile), []byte(etag), 0644)
+}
+
+// cachedETag returns the ETag of the "cacheFile", if it exists and it matches the `Checksum`, if any,
+// so the server can respond that it's not modified instead of sending it again.
+func (p *Project) cachedETag(cacheFile string) string {
+	if cacheFile == "" || !utils.Exists(cacheFile) {
+		return ""
+	}
+
+	etag, err := ioutil.ReadFile(etagFile(cacheFile))
+	if err != nil {
+		return ""
+	}
+
+	if p.Checksum != "" {
+		if checksum, err := fileChecksum(cacheFile); err != nil || p.verify(checksum) != nil {
+			return ""
+		}
+	}
+
+	return string(etag)
+}
+
+// fileChecksum returns the SHA256 hex digest of the "path" file.
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
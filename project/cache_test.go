@@ -0,0 +1,118 @@
+package project
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// Do not use the user's cache directory.
+	dir, err := ioutil.TempDir("", "iris-cli-cache")
+	if err != nil {
+		panic(err)
+	}
+
+	userCacheDir = func() (string, error) { return dir, nil }
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestProjectInstallCache(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	)
+
+	var requests int
+	repo, closeProvider := newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(body)
+	})
+	defer closeProvider()
+
+	install := func(p *Project) error {
+		dest := newTestDest(t)
+		defer os.RemoveAll(dest)
+
+		p.Dest = dest
+		return p.Install()
+	}
+
+	p := New("project", repo)
+	p.Offline = true
+	if err := install(p); err == nil {
+		t.Fatalf("expected an error for an offline installation without cache")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := install(New("project", repo)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if expected, got := 1, requests; expected != got {
+		t.Fatalf("expected %d requests but got %d", expected, got)
+	}
+
+	p = New("project", repo)
+	p.Offline = true
+	if err := install(p); err != nil {
+		t.Fatal(err)
+	}
+
+	p = New("project", repo)
+	p.NoCache = true
+	if err := install(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := 2, requests; expected != got {
+		t.Fatalf("expected %d requests but got %d", expected, got)
+	}
+}
+
+func TestProjectInstallCacheNotModified(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	)
+
+	const etag = `"v1"`
+	var requests, downloads int
+	repo, closeProvider := newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		downloads++
+		w.Header().Set("ETag", etag)
+		w.Write(body)
+	})
+	defer closeProvider()
+
+	for i := 0; i < 3; i++ {
+		dest := newTestDest(t)
+		p := New("project", repo)
+		p.Dest = dest
+		p.CacheTTL = time.Nanosecond // always expired.
+		err := p.Install()
+		os.RemoveAll(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if expected, got := 3, requests; expected != got {
+		t.Fatalf("expected %d requests but got %d", expected, got)
+	}
+
+	if expected, got := 1, downloads; expected != got {
+		t.Fatalf("expected %d downloads but got %d", expected, got)
+	}
+}
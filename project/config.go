@@ -0,0 +1,89 @@
+package project
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"runtime"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigFilename is the name of the optional file inside a project's root which configures its installation,
+// e.g. the commands which run after its extraction. It's not extracted to the destination.
+//
+// Example contents:
+//
+//	postInstall:
+//	  - go generate ./...
+//	  - npm install --prefix ./web
+//	appName: starter
+const ConfigFilename = ".iris-cli.yaml"
+
+// config is the contents of the `ConfigFilename`.
+type config struct {
+	PostInstall []string `yaml:"postInstall"`
+	// AppName is the token which is replaced with the `Project.AppName`, see `Project.AppNameToken`.
+	AppName string `yaml:"appName"`
+}
+
+// readConfig reads the `ConfigFilename` of the "files", if any,
+// and returns the rest of the files, which should be extracted.
+func readConfig(files []*zip.File, compressedRootFolder string) (*config, []*zip.File, error) {
+	c := new(config)
+	name := compressedRootFolder + ConfigFilename
+
+	for i, f := range files {
+		if f.Name != name {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err = yaml.Unmarshal(b, c); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", ConfigFilename, err)
+		}
+
+		rest := make([]*zip.File, 0, len(files)-1)
+		rest = append(rest, files[:i]...)
+		return c, append(rest, files[i+1:]...), nil
+	}
+
+	return c, files, nil
+}
+
+// postInstall runs the `PostInstall` commands inside the destination directory,
+// if the `Confirm` allows them.
+func (p *Project) postInstall(ctx context.Context) error {
+	if len(p.PostInstall) == 0 || p.Confirm == nil || !p.Confirm(p.PostInstall) {
+		return nil
+	}
+
+	for _, command := range p.PostInstall {
+		p.logf("run <%s>", command)
+		if err := runCommand(ctx, p.Dest, shell[0], append(shell[1:], command)...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// shell is the program and its arguments which run a command line.
+var shell = func() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C"}
+	}
+
+	return []string{"sh", "-c"}
+}()
@@ -0,0 +1,76 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+func TestProjectInstallPostInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands of the test require a unix shell")
+	}
+
+	repo, closeProvider := newTestProvider(t, newTestArchive(t,
+		testFile{"project-master/" + ConfigFilename, "postInstall:\n  - echo generated > generated.txt\n"},
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	defer closeProvider()
+
+	tests := []struct {
+		name     string
+		confirm  func([]string) bool
+		expected bool
+	}{
+		{"no confirm", nil, false},
+		{"declined", func([]string) bool { return false }, false},
+		{"confirmed", func([]string) bool { return true }, true},
+	}
+
+	for _, tt := range tests {
+		dest := newTestDest(t)
+		defer os.RemoveAll(dest)
+
+		var commands []string
+		p := New("project", repo)
+		p.Dest = dest
+		if tt.confirm != nil {
+			p.Confirm = func(c []string) bool {
+				commands = c
+				return tt.confirm(c)
+			}
+		}
+
+		installed, err := p.Installation(context.Background())
+		if err != nil {
+			t.Fatalf("[%s] %v", tt.name, err)
+		}
+
+		if expected := []string{"echo generated > generated.txt"}; !reflect.DeepEqual(expected, installed.PostInstall) {
+			t.Fatalf("[%s] expected post install commands: %v but got: %v", tt.name, expected, installed.PostInstall)
+		}
+
+		if tt.confirm != nil && !reflect.DeepEqual(installed.PostInstall, commands) {
+			t.Fatalf("[%s] expected to confirm the commands: %v but got: %v", tt.name, installed.PostInstall, commands)
+		}
+
+		if utils.Exists(filepath.Join(dest, ConfigFilename)) {
+			t.Fatalf("[%s] expected the %s to not be extracted", tt.name, ConfigFilename)
+		}
+
+		generated := filepath.Join(dest, "generated.txt")
+		if got := utils.Exists(generated); tt.expected != got {
+			t.Fatalf("[%s] expected the command to run: %v", tt.name, tt.expected)
+		}
+
+		if tt.expected {
+			expectFile(t, generated, "generated\n")
+		}
+	}
+}
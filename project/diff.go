@@ -0,0 +1,163 @@
+package project
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// The statuses of a file's difference, see `Project.Diff`.
+const (
+	// DiffAdded is a file of the template which does not exist locally.
+	DiffAdded = "added"
+	// DiffRemoved is a local file which does not exist in the template.
+	DiffRemoved = "removed"
+	// DiffChanged is a file whose local contents differ from the template's ones.
+	DiffChanged = "changed"
+)
+
+// FileDiff is a file which differs between a project's template and a local directory.
+type FileDiff struct {
+	// Path is the slash-separated path of the file, relative to the project's directory.
+	Path string
+	// Status is one of the DiffXXX statuses, e.g. `DiffChanged`.
+	Status string
+	// TemplateChecksum is the SHA256 hex digest of the template's file, empty if it's removed.
+	TemplateChecksum string
+	// LocalChecksum is the SHA256 hex digest of the local file, empty if it's added.
+	LocalChecksum string
+}
+
+// Diff same as `DiffContext` with a background context.
+func (p *Project) Diff(localDir string) ([]FileDiff, error) {
+	return p.DiffContext(context.Background(), localDir)
+}
+
+// DiffContext reports the files which differ between the project's template, downloaded as `Install` does,
+// and the "localDir", sorted by path. Nothing is written to the "localDir".
+//
+// The template is installed as the local project, so its files are compared after the module rename:
+// if the `Module` is empty then it's the one of the local go.mod. The local files which are not in the template
+// are reported only if they are recorded by the `Installed` files of `ReadManifest`, if any,
+// so the files which were added locally are not reported as removed. Symbolic links are not compared.
+func (p *Project) DiffContext(ctx context.Context, localDir string) ([]FileDiff, error) {
+	localDir = utils.Dest(localDir)
+
+	tmp, err := ioutil.TempDir("", "iris-cli-diff")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	template := *p
+	template.Dest = tmp
+	template.FS = nil
+	template.Overwrite = OverwriteForce
+	template.DryRun = false
+	template.Tidy, template.Build, template.Gitignore, template.GitInit = false, false, false, false
+	template.Confirm = nil // the post install commands are not run.
+	if template.Module == "" {
+		if b, err := ioutil.ReadFile(filepath.Join(localDir, filepath.FromSlash(p.ModuleDir), "go.mod")); err == nil {
+			template.Module = string(utils.ModulePath(b))
+		}
+	}
+
+	if _, err = template.install(ctx); err != nil {
+		return nil, err
+	}
+
+	local, err := p.localFiles(localDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []FileDiff
+	for _, name := range fileNames(template.Installed, local) {
+		templateChecksum, ok := template.Installed[name]
+		if ok && templateChecksum == "" {
+			continue // symbolic link.
+		}
+
+		localChecksum := local[name]
+		if !ok {
+			if localChecksum == "" {
+				continue
+			}
+
+			diffs = append(diffs, FileDiff{Path: name, Status: DiffRemoved, LocalChecksum: localChecksum})
+			continue
+		}
+
+		if localChecksum == "" {
+			localChecksum, _ = fileChecksum(filepath.Join(localDir, filepath.FromSlash(name))) // empty if missing.
+		}
+
+		switch localChecksum {
+		case templateChecksum:
+			continue
+		case "":
+			diffs = append(diffs, FileDiff{Path: name, Status: DiffAdded, TemplateChecksum: templateChecksum})
+		default:
+			diffs = append(diffs, FileDiff{Path: name, Status: DiffChanged, TemplateChecksum: templateChecksum, LocalChecksum: localChecksum})
+		}
+	}
+
+	return diffs, nil
+}
+
+// localFiles returns the SHA256 hex digests of the regular files of "dir" which may be removed from the template,
+// by their slash-separated path: the `Installed` ones, if any, otherwise all of them except
+// the .git directory, the `ManifestFilename` and the files which would not be extracted, see `Exclude`.
+func (p *Project) localFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+
+	if p.Installed != nil {
+		for name := range p.Installed {
+			if checksum, err := fileChecksum(filepath.Join(dir, filepath.FromSlash(name))); err == nil {
+				files[name] = checksum
+			}
+		}
+
+		return files, nil
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		name := filepath.ToSlash(rel)
+		if name == ManifestFilename || !p.shouldExtract(name) {
+			return nil
+		}
+
+		checksum, err := fileChecksum(path)
+		if err != nil {
+			return err
+		}
+
+		files[name] = checksum
+		return nil
+	})
+
+	return files, err
+}
@@ -0,0 +1,84 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProjectDiff(t *testing.T) {
+	repo, closeProvider := newTestProvider(t, newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n\nimport _ \"github.com/author/project/sub\"\n"},
+		testFile{"project-master/a.go", "package main\n\n// a\n"},
+		testFile{"project-master/b.go", "package main\n\n// b\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := New("project", repo)
+	p.Dest = dest
+	p.Module = "newproject"
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Local changes.
+	if err := ioutil.WriteFile(filepath.Join(dest, "a.go"), []byte("package main\n\n// a local\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dest, "c.go"), []byte("package main\n\n// c local\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dest, "b.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The module is the local one, so the renamed imports of main.go are not a difference.
+	diffs, err := New("project", repo).Diff(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"a.go changed", "b.go added", "c.go removed"}
+	if got := diffStatuses(diffs); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected diffs:\n%v\nbut got:\n%v", expected, got)
+	}
+
+	for _, diff := range diffs {
+		if (diff.TemplateChecksum == "") != (diff.Status == DiffRemoved) || (diff.LocalChecksum == "") != (diff.Status == DiffAdded) {
+			t.Fatalf("unexpected checksums of %s diff: %#+v", diff.Status, diff)
+		}
+	}
+
+	// The locally added files are not reported through the manifest.
+	installed, err := ReadManifest(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diffs, err = installed.Diff(dest); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []string{"a.go changed", "b.go added"}
+	if got := diffStatuses(diffs); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected diffs:\n%v\nbut got:\n%v", expected, got)
+	}
+
+	if _, err = os.Stat(filepath.Join(dest, "b.go")); !os.IsNotExist(err) {
+		t.Fatalf("expected the diff not to write to the local directory but got: %v", err)
+	}
+}
+
+func diffStatuses(diffs []FileDiff) (statuses []string) {
+	for _, diff := range diffs {
+		statuses = append(statuses, diff.Path+" "+diff.Status)
+	}
+
+	return
+}
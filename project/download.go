@@ -0,0 +1,655 @@
+package project
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// DefaultTimeout is the time limit of a download, including the reading of the archive,
+// when `Project.Client` is nil.
+const DefaultTimeout = 5 * time.Minute
+
+// defaultClient is the http client used when `Project.Client` is nil,
+// it respects the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+// DefaultRetries is the number of download retries when `Project.Retries` is zero.
+const DefaultRetries = 3
+
+// The version which is downloaded by default and the one which is tried when it does not exist,
+// as new repositories use "main" as their default branch.
+const (
+	defaultVersion         = "master"
+	defaultVersionFallback = "main"
+)
+
+// retryBackoff is the wait duration before the first download retry, it's doubled on each attempt.
+var retryBackoff = time.Second
+
+// download streams the project's archive to a file and returns its path
+// and a function which releases it, the caller is responsible to call it
+// when the file is no longer used. The archive is read from or saved to the cache,
+// unless `NoCache` is true. If the provider's archive URL fails then its fallback one, if any, is tried.
+// If the "master" version does not exist then the "main" one is downloaded instead and the `Version` is set to it,
+// see `DefaultBranch` too.
+func (p *Project) download(ctx context.Context) (string, func(), error) {
+	if p.Archive != "" || p.Dir != "" {
+		return p.local()
+	}
+
+	p.normalizeVersion()
+	if p.GoProxy {
+		if err := p.resolveProxyVersion(ctx); err != nil {
+			return "", nil, err
+		}
+	}
+	p.resolveDefaultBranch(ctx)
+
+	cacheFile, err := p.cacheFile()
+	if err != nil {
+		if p.Offline {
+			return "", nil, err
+		}
+		// Continue without cache.
+	} else if ok, err := p.cached(cacheFile); ok || err != nil {
+		if ok {
+			p.logf("use cached archive <%s>", cacheFile)
+		}
+		return cacheFile, func() {}, err
+	}
+
+	if p.Offline {
+		return "", nil, fmt.Errorf("repository <%s> (version <%s>) is %w", p.Repo, p.Version, ErrNotCached)
+	}
+
+	provider, zipURL, fallbackURL := p.archiveURL()
+	options := p.downloadOptions(provider)
+	p.logf("download <%s>", zipURL)
+
+	// Ask the server whether the expired cached archive is changed.
+	if etag := p.cachedETag(cacheFile); etag != "" {
+		options = append(options, ifNoneMatch(etag))
+	}
+
+	retries := p.Retries
+	if retries == 0 {
+		retries = DefaultRetries
+	}
+
+	// Download to the cache directory so it can be moved to the cache file.
+	dir := ""
+	if cacheFile != "" {
+		dir = filepath.Dir(cacheFile)
+	}
+
+	var partial *fetched // of the last failed attempt, if it can be resumed.
+	defer func() {
+		if partial != nil {
+			os.Remove(partial.file)
+		}
+	}()
+
+	for attempt := 0; ; attempt++ {
+		result, err := p.fetch(ctx, dir, zipURL, partial, options...)
+		if err == nil {
+			partial = nil
+			if cacheFile != "" && os.Rename(result.file, cacheFile) == nil {
+				saveETag(cacheFile, result.etag)
+				return cacheFile, func() {}, nil
+			}
+
+			return result.file, func() { os.Remove(result.file) }, nil
+		}
+		partial = result
+
+		if code, ok := utils.IsStatus(err); ok && code == http.StatusNotModified {
+			p.logf("use cached archive <%s>, it's not modified", cacheFile)
+			now := time.Now()
+			os.Chtimes(cacheFile, now, now) // renew its TTL.
+			return cacheFile, func() {}, nil
+		}
+
+		wait, ok := retryAfter(err, attempt)
+		if !ok || attempt >= retries || ctx.Err() != nil {
+			if _, rateLimited := utils.IsRateLimited(err); fallbackURL != "" && !rateLimited && ctx.Err() == nil {
+				p.logf("download <%s> failed, trying <%s>", zipURL, fallbackURL)
+				zipURL, fallbackURL = fallbackURL, ""
+				if partial != nil {
+					os.Remove(partial.file)
+					partial = nil
+				}
+				attempt = -1
+				continue
+			}
+
+			if code, ok := utils.IsStatus(err); ok && code == http.StatusNotFound && p.Version == defaultVersion {
+				p.logf("version <%s> not found, trying <%s>", defaultVersion, defaultVersionFallback)
+				p.Version = defaultVersionFallback
+				if zipFile, release, fallbackErr := p.download(ctx); fallbackErr == nil {
+					return zipFile, release, nil
+				}
+				p.Version = defaultVersion
+			}
+
+			return "", nil, p.downloadError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// ArchiveChecksum downloads the project's archive, without extracting it, and returns its SHA256 hex digest,
+// e.g. for template authors to publish the `Checksum` of a version. The cached archive is used, if any.
+func (p *Project) ArchiveChecksum() (string, error) {
+	return p.ArchiveChecksumContext(context.Background())
+}
+
+// ArchiveChecksumContext same as `ArchiveChecksum` but it accepts a context which can cancel the download.
+func (p *Project) ArchiveChecksumContext(ctx context.Context) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+
+	zipFile, release, err := p.download(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	return fileChecksum(zipFile)
+}
+
+// Export writes the project's archive, as it's downloaded or read from the cache, to the "zipPath" file,
+// e.g. to be stored for offline installations through the `Archive` field (see `NewFromArchive`).
+// The archive can be a zip or a tar.gz one, depending on the provider. The "p" is not modified.
+func (p *Project) Export(zipPath string) error {
+	return p.ExportContext(context.Background(), zipPath)
+}
+
+// ExportContext same as `Export` but it accepts a context which can cancel the download.
+func (p *Project) ExportContext(ctx context.Context, zipPath string) error {
+	c := *p
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	zipFile, release, err := c.download(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	src, err := os.Open(zipFile)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err = os.MkdirAll(filepath.Dir(zipPath), os.ModePerm); err != nil {
+		return err
+	}
+
+	// Write to a temporary file next to the "zipPath" first, so an existing file is replaced only on success.
+	dst, err := ioutil.TempFile(filepath.Dir(zipPath), ".iris-cli-export-*")
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(dst.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(dst.Name(), zipPath)
+	}
+	if err != nil {
+		os.Remove(dst.Name())
+		return err
+	}
+
+	c.logf("export <%s>", zipPath)
+	return nil
+}
+
+// Resolve returns the archive URL which `Install` downloads, or the local `Archive` or `Dir`,
+// and the resolved destination directory of the project, without any network work, e.g. to verify the options.
+// As the project's go.mod is not read, an empty `Module` is assumed to be the repository's path,
+// e.g. "github.com/kataras/iris-cli", for the destination. The "p" is not modified.
+func (p *Project) Resolve() (string, string, error) {
+	c := *p
+	if err := c.Validate(); err != nil {
+		return "", "", err
+	}
+
+	module := c.Module
+	if c.Archive != "" || c.Dir != "" {
+		source := c.Archive
+		if source == "" {
+			source = c.Dir
+		}
+
+		return source, resolveDest(c.Dest, module, c.GOPATH), nil
+	}
+
+	c.normalizeVersion()
+	provider, zipURL, _ := c.archiveURL()
+	if module == "" {
+		if c.GoProxy {
+			module = c.Repo
+		} else {
+			_, repo := ProviderOf(c.Repo)
+			module = provider.Host + "/" + repo
+		}
+	}
+
+	return zipURL, resolveDest(c.Dest, module, c.GOPATH), nil
+}
+
+// normalizeVersion keeps the first word of the `Version` and sets "latest" to the default version.
+func (p *Project) normalizeVersion() {
+	p.Version = strings.Split(p.Version, " ")[0]
+	if p.Version == "latest" {
+		p.Version = defaultVersion
+	}
+}
+
+// archiveURL returns the provider of the project's repository, its archive URL,
+// e.g. https://codeload.github.com/kataras/iris-cli/zip/refs/heads/master,
+// and the fallback archive URL of the provider, if any. On `GoProxy` it's the module proxy's one.
+func (p *Project) archiveURL() (*Provider, string, string) {
+	provider, repo := ProviderOf(p.Repo)
+	if p.GoProxy {
+		provider, repo = goProxyProvider(goProxyURLs()), p.Repo
+	}
+
+	fallbackURL := ""
+	if provider.FallbackArchiveURL != nil {
+		fallbackURL = provider.FallbackArchiveURL(repo, p.Version)
+	}
+
+	return provider, provider.ArchiveURL(repo, p.Version), fallbackURL
+}
+
+// local returns the local `Archive` or an archive of the local `Dir`, instead of downloading it.
+func (p *Project) local() (string, func(), error) {
+	zipFile, release := p.Archive, func() {}
+	if zipFile == "" {
+		p.logf("archive directory <%s>", p.Dir)
+		var err error
+		if zipFile, err = zipDir(p.Dir); err != nil {
+			return "", nil, err
+		}
+		release = func() { os.Remove(zipFile) }
+	}
+
+	checksum, err := fileChecksum(zipFile)
+	if err == nil {
+		err = p.verify(checksum)
+	}
+
+	if err != nil {
+		release()
+		return "", nil, err
+	}
+
+	return zipFile, release, nil
+}
+
+// zipDir writes the files of "dir" to a temporary zip file, inside a root folder, and returns its path.
+func zipDir(dir string) (string, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if !info.IsDir() {
+		return "", fmt.Errorf("<%s> is not a directory", dir)
+	}
+
+	f, err := ioutil.TempFile("", "iris-cli-*.zip")
+	if err != nil {
+		return "", err
+	}
+
+	w := zip.NewWriter(f)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		h, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+
+		h.Name = "root/"
+		if rel != "." {
+			h.Name += filepath.ToSlash(rel)
+		}
+
+		if info.IsDir() {
+			if rel != "." {
+				h.Name += "/"
+			}
+			_, err = w.CreateHeader(h)
+			return err
+		}
+
+		h.Method = zip.Deflate
+		fw, err := w.CreateHeader(h)
+		if err != nil {
+			return err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			_, err = fw.Write([]byte(target))
+			return err
+		}
+
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		_, err = fw.Write(contents)
+		return err
+	})
+
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// httpClient returns the http client of the downloads, the `Client` or the default one, with the `Timeout`, if any.
+func (p *Project) httpClient() *http.Client {
+	client := p.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	if p.Timeout > 0 {
+		c := *client
+		c.Timeout = p.Timeout
+		client = &c
+	}
+
+	return client
+}
+
+// downloadOptions returns the options of a request to the "provider",
+// they set the token or, if there is no token, the .netrc credentials of the host.
+// The token is never sent to a module proxy, see `GoProxy`.
+func (p *Project) downloadOptions(provider *Provider) []utils.DownloadOption {
+	if token := p.token(provider); token != "" && !p.GoProxy {
+		return []utils.DownloadOption{provider.authorize(token)}
+	}
+
+	return []utils.DownloadOption{netrcAuth()}
+}
+
+// fetched is the file of a download and the ETag of its response, if any.
+type fetched struct {
+	file string
+	etag string
+}
+
+// fetch downloads the "zipURL" to a temporary file inside "dir".
+// If "dir" is empty then the default directory for temporary files is used instead.
+// If the "resume" is not nil then the download continues from the end of its file,
+// when the server supports range requests and the ETag is the same, otherwise it starts over.
+// On failure, it returns the partial download if the server supports range requests.
+func (p *Project) fetch(ctx context.Context, dir, zipURL string, resume *fetched, options ...utils.DownloadOption) (*fetched, error) {
+	var offset int64
+	if resume != nil {
+		if info, err := os.Stat(resume.file); err == nil {
+			offset = info.Size()
+		}
+		options = append(options, rangeOption(offset, resume.etag))
+	}
+
+	r, err := utils.DownloadReaderContext(ctx, p.httpClient(), zipURL, nil, options...)
+	if err != nil {
+		return resume, err // keep the partial download for the next attempt.
+	}
+	defer r.Close()
+
+	header := utils.ResponseHeader(r)
+
+	var f *os.File
+	if resume != nil && strings.HasPrefix(header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+		p.logf("resume download from %d bytes", offset)
+		f, err = os.OpenFile(resume.file, os.O_WRONLY|os.O_APPEND, 0)
+	} else {
+		if resume != nil {
+			// The range is not supported or the archive was changed, start over.
+			os.Remove(resume.file)
+			offset = 0
+		}
+		f, err = ioutil.TempFile(dir, "iris-cli-*.zip")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader = r
+	if p.Progress != nil {
+		body = utils.ProgressReader(r, utils.ContentLength(r), func(current, total int64) {
+			if total >= 0 {
+				total += offset
+			}
+			p.Progress(current+offset, total)
+		})
+	}
+
+	if p.Reader != nil {
+		var b []byte
+		if b, err = p.Reader(body); err == nil {
+			_, err = f.Write(b)
+		}
+	} else {
+		_, err = io.Copy(f, body)
+	}
+
+	readErr := err
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if readErr != nil && p.Reader == nil && ctx.Err() == nil && header.Get("Content-Encoding") == "" &&
+		(header.Get("Accept-Ranges") == "bytes" || header.Get("Content-Range") != "") {
+		// The written bytes are the archive's ones, so the rest can be requested on retry.
+		return &fetched{file: f.Name(), etag: header.Get("ETag")}, err
+	}
+
+	if err == nil && p.Checksum != "" {
+		var checksum string
+		if checksum, err = fileChecksum(f.Name()); err == nil {
+			err = p.verify(checksum)
+		}
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, err
+	}
+
+	return &fetched{file: f.Name(), etag: header.Get("ETag")}, nil
+}
+
+// ifNoneMatch returns a download option which requests the archive only if its ETag is not the "etag".
+func ifNoneMatch(etag string) utils.DownloadOption {
+	return func(r *http.Request) error {
+		r.Header.Set("If-None-Match", etag)
+		return nil
+	}
+}
+
+// rangeOption returns a download option which requests the bytes of an archive after the "offset",
+// if its "etag" is still the same, otherwise the whole archive. The response is not encoded,
+// so the offset matches the archive's bytes.
+func rangeOption(offset int64, etag string) utils.DownloadOption {
+	return func(r *http.Request) error {
+		r.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		r.Header.Set("Accept-Encoding", "identity")
+		if etag != "" {
+			r.Header.Set("If-Range", etag)
+		}
+
+		return nil
+	}
+}
+
+// verify checks the "checksum" of the downloaded archive against the expected `Checksum`, if any.
+func (p *Project) verify(checksum string) error {
+	if p.Checksum != "" && !strings.EqualFold(checksum, p.Checksum) {
+		return fmt.Errorf("%w for repository <%s> (version <%s>): expected %s but got %s", ErrChecksumMismatch, p.Repo, p.Version, p.Checksum, checksum)
+	}
+
+	return nil
+}
+
+// downloadError returns a descriptive error for a failed download.
+func (p *Project) downloadError(err error) error {
+	if reset, ok := utils.IsRateLimited(err); ok {
+		resets := "later"
+		if !reset.IsZero() {
+			resets = "at " + reset.Format(time.Kitchen)
+		}
+
+		return wrapKind(ErrRateLimited, err, "%v for repository <%s>, it resets %s, please set a token (IRIS_CLI_TOKEN or GITHUB_TOKEN) for a higher limit: %v", ErrRateLimited, p.Repo, resets, err)
+	}
+
+	if code, ok := utils.IsStatus(err); ok {
+		switch code {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return wrapKind(ErrAccessDenied, err, "%v to repository <%s>, please check your token: %v", ErrAccessDenied, p.Repo, err)
+		case http.StatusNotFound:
+			return fmt.Errorf("%w: <%s> (version <%s>): HTTP %d", ErrRepoNotFound, p.Repo, p.Version, code)
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return wrapKind(ErrNetwork, err, "%v: %v", ErrNetwork, err)
+	}
+
+	return err
+}
+
+// retryAfter reports whether a failed download should be retried and how long to wait before that.
+// Only network errors and 5xx or 429 responses are retried.
+func retryAfter(err error, attempt int) (time.Duration, bool) {
+	wait := retryBackoff << uint(attempt)
+
+	if _, ok := utils.IsRateLimited(err); ok {
+		return 0, false // it resets much later.
+	}
+
+	var statusErr utils.StatusError
+	if errors.As(err, &statusErr) {
+		switch code := statusErr.StatusCode; {
+		case code == http.StatusTooManyRequests:
+			if d := parseRetryAfter(statusErr.Header.Get("Retry-After")); d > 0 {
+				wait = d
+			}
+			return wait, true
+		case code >= 500:
+			return wait, true
+		default:
+			return 0, false
+		}
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return wait, true
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Op == "parse" {
+		return 0, false
+	}
+
+	var netErr net.Error
+	return wait, errors.As(err, &netErr)
+}
+
+// parseRetryAfter returns the duration of a "Retry-After" header value,
+// which can be a number of seconds or a date.
+func parseRetryAfter(s string) time.Duration {
+	if s == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(s); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(s); err == nil {
+		return time.Until(t)
+	}
+
+	return 0
+}
+
+// token returns the `Token` field or the IRIS_CLI_TOKEN environment variable or,
+// for the `GitHub` provider only, the GITHUB_TOKEN one, so a GitHub credential is never sent to other hosts.
+func (p *Project) token(provider *Provider) string {
+	if p.Token != "" {
+		return p.Token
+	}
+
+	if token := os.Getenv("IRIS_CLI_TOKEN"); token != "" {
+		return token
+	}
+
+	if provider == GitHub {
+		return os.Getenv("GITHUB_TOKEN")
+	}
+
+	return ""
+}
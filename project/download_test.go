@@ -0,0 +1,493 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProjectDownloadRetry(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	)
+
+	defer func(d time.Duration) { retryBackoff = d }(retryBackoff)
+	retryBackoff = time.Millisecond
+
+	var requests int
+	repo, closeProvider := newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write(body)
+		}
+	})
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := New("project", repo)
+	p.Dest = dest
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := 3, requests; expected != got {
+		t.Fatalf("expected %d requests but got %d", expected, got)
+	}
+
+	// Client errors should not be retried.
+	requests = 0
+	repo, closeNotFoundProvider := newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer closeNotFoundProvider()
+
+	p = New("project", repo)
+	p.Version = "v1.0.0" // the default version falls back to "main" on 404.
+	p.Dest = dest
+	if err := p.Install(); err == nil {
+		t.Fatalf("expected a not found error")
+	}
+
+	if expected, got := 1, requests; expected != got {
+		t.Fatalf("expected %d requests but got %d", expected, got)
+	}
+}
+
+type countTransport struct {
+	requests int
+}
+
+func (t *countTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.requests++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestProjectDownloadClient(t *testing.T) {
+	repo, closeProvider := newTestProvider(t, newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	transport := new(countTransport)
+	p := New("project", repo)
+	p.Dest = dest
+	p.Client = &http.Client{Transport: transport}
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := 1, transport.requests; expected != got {
+		t.Fatalf("expected %d requests through the custom client but got %d", expected, got)
+	}
+}
+
+// archiveTransport serves the "body" archive to any request and records the requests' headers.
+type archiveTransport struct {
+	body    []byte
+	headers []http.Header
+}
+
+func (t *archiveTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.headers = append(t.headers, r.Header.Clone())
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(bytes.NewReader(t.body)),
+		Request:    r,
+	}, nil
+}
+
+func TestProjectDownloadToken(t *testing.T) {
+	for key, value := range map[string]string{"GITHUB_TOKEN": "secret", "IRIS_CLI_TOKEN": "", "NETRC": filepath.Join(os.TempDir(), "iris-cli-missing-netrc")} {
+		defer os.Setenv(key, os.Getenv(key))
+		os.Setenv(key, value)
+	}
+
+	tests := []struct {
+		repo        string
+		credentials bool
+	}{
+		{"author/project", true},
+		{"gitlab.com/author/project", false},
+		{"bitbucket.org/author/project", false},
+	}
+
+	for _, tt := range tests {
+		dest := newTestDest(t)
+		defer os.RemoveAll(dest)
+
+		transport := &archiveTransport{body: newTestArchive(t,
+			testFile{"project-master/main.go", "package main\n"},
+			testFile{"project-master/go.mod", "module github.com/author/project\n"},
+		)}
+		p := New("project", tt.repo)
+		p.Dest = dest
+		p.NoCache = true
+		p.Client = &http.Client{Transport: transport}
+		if err := p.Install(); err != nil {
+			t.Fatalf("[%s] %v", tt.repo, err)
+		}
+
+		for _, header := range transport.headers {
+			credentials := header.Get("Authorization") != "" || header.Get("PRIVATE-TOKEN") != ""
+			if credentials != tt.credentials {
+				t.Fatalf("[%s] expected credentials: %v but got headers: %v", tt.repo, tt.credentials, header)
+			}
+		}
+	}
+}
+
+func TestProjectDownloadTimeout(t *testing.T) {
+	repo, closeProvider := newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1024")
+		w.Write([]byte("PK"))
+		w.(http.Flusher).Flush()
+		select { // stall the body.
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := New("project", repo)
+	p.Dest = dest
+	p.NoCache = true
+	p.Retries = -1
+	p.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	if err := p.Install(); err == nil {
+		t.Fatalf("expected a timeout error")
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the download to time out but it took %s", elapsed)
+	}
+}
+
+func TestProjectDownloadMainFallback(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"project-main/main.go", "package main\n"},
+		testFile{"project-main/go.mod", "module github.com/author/project\n"},
+	)
+
+	var requested []string
+	repo, closeProvider := newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, path.Base(r.URL.Path))
+		if path.Base(r.URL.Path) != "main.zip" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	})
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := New("project", repo)
+	p.Dest = dest
+	installed, err := p.Installation(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"master.zip", "main.zip"}; !reflect.DeepEqual(expected, requested) {
+		t.Fatalf("expected requests: %v but got: %v", expected, requested)
+	}
+
+	if expected, got := "main", installed.Version; expected != got {
+		t.Fatalf("expected version: %s but got: %s", expected, got)
+	}
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n")
+
+	// Other versions do not fall back.
+	requested = nil
+	p = New("project", repo)
+	p.Version = "v1.0.0"
+	p.Dest = newTestDest(t)
+	defer os.RemoveAll(p.Dest)
+	if err := p.Install(); !errors.Is(err, ErrRepoNotFound) {
+		t.Fatalf("expected error: %v but got: %v", ErrRepoNotFound, err)
+	}
+
+	if expected := []string{"v1.0.0.zip"}; !reflect.DeepEqual(expected, requested) {
+		t.Fatalf("expected requests: %v but got: %v", expected, requested)
+	}
+}
+
+func TestProjectDownloadFallbackURL(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"project-v1.0/main.go", "package main\n"},
+		testFile{"project-v1.0/go.mod", "module github.com/author/project\n"},
+	)
+
+	var requested []string
+	repo, closeProvider := newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if strings.HasPrefix(r.URL.Path, "/refs/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write(body)
+	})
+	defer closeProvider()
+
+	provider, _ := ProviderOf(repo)
+	archiveURL := provider.ArchiveURL
+	provider.ArchiveURL = func(repo, version string) string {
+		return strings.Replace(archiveURL(repo, version), "/"+repo+"/", "/refs/heads/", 1)
+	}
+	provider.FallbackArchiveURL = archiveURL
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := New("project", repo)
+	p.Version = "v1.0"
+	p.Dest = dest
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"/refs/heads/v1.0.zip", "/author/project/v1.0.zip"}; !reflect.DeepEqual(expected, requested) {
+		t.Fatalf("expected requests: %v but got: %v", expected, requested)
+	}
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n")
+}
+
+func TestProjectDownloadSlashVersion(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"project-feature-login/main.go", "package main\n"},
+		testFile{"project-feature-login/go.mod", "module github.com/author/project\n"},
+	)
+
+	var requested string
+	repo, closeProvider := newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.EscapedPath()
+		w.Write(body)
+	})
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := New("project", repo)
+	p.Version = "feature/login"
+	p.Dest = dest
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "/author/project/feature%2Flogin.zip"; expected != requested {
+		t.Fatalf("expected request path: %s but got: %s", expected, requested)
+	}
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n")
+}
+
+func TestProjectResolve(t *testing.T) {
+	defer os.Setenv("GOPATH", os.Getenv("GOPATH"))
+	os.Setenv("GOPATH", "")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		project Project
+		url     string
+		dest    string
+	}{
+		{Project{Repo: "kataras/iris-cli", Version: "latest"}, "https://codeload.github.com/kataras/iris-cli/zip/refs/heads/master", filepath.Join(wd, "iris-cli")},
+		{Project{Repo: "gitlab.com/group/app", Version: "v1.0.0", Module: "github.com/me/api"}, "https://gitlab.com/group/app/-/archive/v1.0.0/app-v1.0.0.zip", filepath.Join(wd, "api")},
+		{Project{Repo: "https://github.com/kataras/iris-cli.git", Dest: "./custom"}, "https://codeload.github.com/kataras/iris-cli/zip/refs/heads/master", filepath.Join(wd, "custom")},
+		{Project{Archive: "./starter.zip", Dest: "./custom"}, "./starter.zip", filepath.Join(wd, "custom")},
+	}
+
+	for i, tt := range tests {
+		p := tt.project
+		url, dest, err := p.Resolve()
+		if err != nil {
+			t.Fatalf("[%d] %v", i, err)
+		}
+
+		if tt.url != url {
+			t.Fatalf("[%d] expected URL: %s but got: %s", i, tt.url, url)
+		}
+
+		if tt.dest != dest {
+			t.Fatalf("[%d] expected destination: %s but got: %s", i, tt.dest, dest)
+		}
+
+		if !reflect.DeepEqual(tt.project, p) {
+			t.Fatalf("[%d] expected the project to not be modified", i)
+		}
+	}
+
+	if _, _, err = (&Project{Repo: "kataras"}).Resolve(); err == nil {
+		t.Fatalf("expected an invalid repository error")
+	}
+}
+
+func TestProjectArchiveChecksum(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	)
+	repo, closeProvider := newTestProvider(t, body)
+	defer closeProvider()
+
+	p := New("project", repo)
+	p.Dest = newTestDest(t)
+	defer os.RemoveAll(p.Dest)
+
+	checksum, err := p.ArchiveChecksum()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := sha256Hex(string(body)); expected != checksum {
+		t.Fatalf("expected checksum: %s but got: %s", expected, checksum)
+	}
+
+	if files, _ := ioutil.ReadDir(p.Dest); len(files) > 0 {
+		t.Fatalf("expected nothing to be extracted but got %d files", len(files))
+	}
+
+	// The published checksum can be verified on installation.
+	p.Checksum = checksum
+	if err = p.Install(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProjectExport(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	)
+	repo, closeProvider := newTestProvider(t, body)
+	defer closeProvider()
+
+	dir := newTestDest(t)
+	defer os.RemoveAll(dir)
+
+	zipPath := filepath.Join(dir, "archives", "project.zip")
+	if err := New("project", repo).Export(zipPath); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(body, b) {
+		t.Fatalf("expected the exported archive to be the downloaded one")
+	}
+
+	// The exported archive can be installed as a local one.
+	dest := filepath.Join(dir, "project")
+	p := NewFromArchive(dest, zipPath)
+	p.Module = "github.com/me/app"
+	if err = p.Install(); err != nil {
+		t.Fatal(err)
+	}
+	expectFile(t, filepath.Join(dest, "go.mod"), "module github.com/me/app\n")
+}
+
+func TestProjectDownloadResume(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n\n// " + strings.Repeat("resumable ", 1000) + "\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	)
+
+	defer func(d time.Duration) { retryBackoff = d }(retryBackoff)
+	retryBackoff = time.Millisecond
+
+	tests := []struct {
+		name     string
+		nextETag string // the ETag of the archive on retry, the download starts over if it's changed.
+	}{
+		{"same archive", `"v1"`},
+		{"changed archive", `"v2"`},
+	}
+
+	for _, tt := range tests {
+		var ranges []string
+		repo, closeProvider := newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+			ranges = append(ranges, r.Header.Get("Range"))
+			if len(ranges) == 1 {
+				// Fail in the middle of the body.
+				w.Header().Set("ETag", `"v1"`)
+				w.Header().Set("Accept-Ranges", "bytes")
+				w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+				w.Write(body[:len(body)/2])
+				return
+			}
+
+			if expected, got := `"v1"`, r.Header.Get("If-Range"); expected != got {
+				t.Errorf("[%s] expected If-Range: %s but got: %s", tt.name, expected, got)
+			}
+
+			w.Header().Set("ETag", tt.nextETag)
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+		})
+
+		dest := newTestDest(t)
+		p := New("project", repo)
+		p.Dest = dest
+		p.NoCache = true
+		p.Checksum = sha256Hex(string(body))
+
+		var progress int64
+		p.Progress = func(current, total int64) {
+			progress = current
+		}
+
+		err := p.Install()
+		closeProvider()
+		os.RemoveAll(dest)
+		if err != nil {
+			t.Fatalf("[%s] %v", tt.name, err)
+		}
+
+		if expected := []string{"", fmt.Sprintf("bytes=%d-", len(body)/2)}; !reflect.DeepEqual(expected, ranges) {
+			t.Fatalf("[%s] expected ranges: %q but got: %q", tt.name, expected, ranges)
+		}
+
+		if expected := int64(len(body)); expected != progress {
+			t.Fatalf("[%s] expected progress to end at: %d but got: %d", tt.name, expected, progress)
+		}
+	}
+}
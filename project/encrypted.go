@@ -0,0 +1,102 @@
+package project
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	cryptozip "github.com/yeka/zip"
+)
+
+// isEncrypted reports whether any of the "files" is password-protected,
+// the standard zip reader can't read their contents, see `Project.Password`.
+func isEncrypted(files []*zip.File) bool {
+	for _, f := range files {
+		if f.Flags&0x1 != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decryptZip writes the entries of the password-protected "archive" zip file, decrypted with the "password"
+// (ZipCrypto or WinZip AES), to a temporary zip file and returns its path. The entries which are not encrypted
+// are copied as they are. A wrong password fails with an `ErrInvalidPassword`.
+// It fails with an `ErrTooLarge` once the decrypted contents exceed the "maxSize" bytes, unless it's negative.
+func decryptZip(archive, password string, maxSize int64) (string, error) {
+	r, err := cryptozip.OpenReader(archive)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	f, err := ioutil.TempFile("", "iris-cli-*.zip")
+	if err != nil {
+		return "", err
+	}
+
+	w := zip.NewWriter(f)
+	remaining := maxSize
+	for _, entry := range r.File {
+		if err = copyDecrypted(w, entry, password, &remaining); err != nil {
+			if errors.Is(err, ErrTooLarge) {
+				err = fmt.Errorf("%w: more than %d bytes, <%s> exceeds the limit", ErrTooLarge, maxSize, entry.Name)
+			}
+			break
+		}
+	}
+
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("archive <%s>: %w", archive, err)
+	}
+
+	return f.Name(), nil
+}
+
+// copyDecrypted writes the decrypted contents of the "entry" to "w", with its name, mode and modification time,
+// they are limited to the "remaining" bytes, see `copyLimited`.
+func copyDecrypted(w *zip.Writer, entry *cryptozip.File, password string, remaining *int64) error {
+	h, err := zip.FileInfoHeader(entry.FileInfo())
+	if err != nil {
+		return err
+	}
+	h.Name = entry.Name
+	if !entry.FileInfo().IsDir() {
+		h.Method = zip.Deflate
+	}
+
+	fw, err := w.CreateHeader(h)
+	if err != nil {
+		return err
+	}
+
+	encrypted := entry.IsEncrypted()
+	if encrypted {
+		entry.SetPassword(password)
+	}
+
+	rc, err := entry.Open()
+	if err == nil {
+		err = copyLimited(fw, rc, remaining)
+		rc.Close()
+	}
+
+	if err != nil && encrypted && !errors.Is(err, ErrTooLarge) {
+		// A wrong ZipCrypto password is detected only by the corrupted contents.
+		return fmt.Errorf("%w: %s: %v", ErrInvalidPassword, entry.Name, err)
+	}
+
+	return err
+}
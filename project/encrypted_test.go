@@ -0,0 +1,81 @@
+package project
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cryptozip "github.com/yeka/zip"
+)
+
+// newTestEncryptedZip returns a zip archive of the "files", encrypted with the "password" and the "method".
+func newTestEncryptedZip(t testing.TB, password string, method cryptozip.EncryptionMethod, files ...testFile) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	w := cryptozip.NewWriter(buf)
+	for _, f := range files {
+		fw, err := w.Encrypt(f.Name, password, method)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = fw.Write([]byte(f.Contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestProjectInstallEncrypted(t *testing.T) {
+	files := []testFile{
+		{"project-master/main.go", "package main\n\nimport _ \"github.com/author/project/sub\"\n"},
+		{"project-master/go.mod", "module github.com/author/project\n"},
+	}
+
+	for _, method := range []cryptozip.EncryptionMethod{cryptozip.StandardEncryption, cryptozip.AES256Encryption} {
+		archive := filepath.Join(newTestDest(t), "project.zip")
+		defer os.RemoveAll(filepath.Dir(archive))
+
+		if err := ioutil.WriteFile(archive, newTestEncryptedZip(t, "secret", method, files...), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		dest := newTestDest(t)
+		defer os.RemoveAll(dest)
+
+		p := NewFromArchive(dest, archive)
+		p.Module = "github.com/me/app"
+		if err := p.Install(); err == nil {
+			t.Fatalf("[%d] expected an error without a password", method)
+		}
+
+		p.Password = "wrong"
+		if err := p.Install(); !errors.Is(err, ErrInvalidPassword) {
+			t.Fatalf("[%d] expected an invalid password error but got: %v", method, err)
+		}
+
+		p.Password = "secret"
+		if err := p.Install(); err != nil {
+			t.Fatalf("[%d] %v", method, err)
+		}
+
+		expectFile(t, filepath.Join(dest, "main.go"), "package main\n\nimport _ \"github.com/me/app/sub\"\n")
+		expectFile(t, filepath.Join(dest, "go.mod"), "module github.com/me/app\n")
+
+		// The limit is enforced while decrypting, before the extraction's size check.
+		p.MaxSize = 20
+		if err := p.Install(); !errors.Is(err, ErrTooLarge) || !strings.Contains(err.Error(), "archive <"+archive+">") {
+			t.Fatalf("[%d] expected error: %v of the archive but got: %v", method, ErrTooLarge, err)
+		}
+	}
+}
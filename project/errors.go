@@ -0,0 +1,53 @@
+package project
+
+import "fmt"
+
+// The errors which the installation of a project can be caused by,
+// the returned errors wrap them, use `errors.Is` to check for them.
+var (
+	// ErrRepoNotFound is caused when the repository or its version does not exist.
+	ErrRepoNotFound = fmt.Errorf("repository not found")
+	// ErrAccessDenied is caused when the repository is private and the token is missing or invalid.
+	ErrAccessDenied = fmt.Errorf("access denied")
+	// ErrRateLimited is caused when the API rate limit of the repository's host is exceeded,
+	// e.g. the 60 requests per hour of the unauthenticated GitHub requests.
+	ErrRateLimited = fmt.Errorf("rate limit exceeded")
+	// ErrNetwork is caused when the repository can't be downloaded because of a network failure.
+	ErrNetwork = fmt.Errorf("network error")
+	// ErrNotCached is caused on `Project.Offline` when the repository's archive is not cached.
+	ErrNotCached = fmt.Errorf("not cached")
+	// ErrChecksumMismatch is caused when the archive does not match the `Project.Checksum`.
+	ErrChecksumMismatch = fmt.Errorf("checksum mismatch")
+	// ErrNotModule is caused when the project does not contain a go.mod file and the `Project.Module` is empty.
+	ErrNotModule = fmt.Errorf("not a go module")
+	// ErrIllegalPath is caused when a file or a link of the archive points outside of the destination.
+	ErrIllegalPath = fmt.Errorf("illegal path")
+	// ErrTooLarge is caused when the uncompressed files of the archive exceed the `Project.MaxSize`.
+	ErrTooLarge = fmt.Errorf("too large")
+	// ErrFilesExist is caused by the `OverwriteFail` policy when files of the destination would be overwritten.
+	ErrFilesExist = fmt.Errorf("files already exist")
+	// ErrCaseCollision is caused on a case-insensitive file system, e.g. of macOS and Windows,
+	// when two files of the archive differ only by case, e.g. "Config.go" and "config.go".
+	ErrCaseCollision = fmt.Errorf("case collision")
+	// ErrInvalidPassword is caused when a password-protected archive can't be decrypted with the `Project.Password`.
+	ErrInvalidPassword = fmt.Errorf("invalid password")
+)
+
+// kindError is an error of a "kind", e.g. `ErrAccessDenied`, which wraps its cause, e.g. a `utils.StatusError`,
+// so both of them are matched by `errors.Is` and `errors.As`. A single "%w" verb can wrap only one of them.
+type kindError struct {
+	kind error
+	msg  string
+	err  error
+}
+
+// wrapKind returns an error of the "kind" which wraps the "err", its message is formatted like `fmt.Sprintf`.
+func wrapKind(kind, err error, format string, args ...interface{}) error {
+	return &kindError{kind: kind, msg: fmt.Sprintf(format, args...), err: err}
+}
+
+func (e *kindError) Error() string { return e.msg }
+
+func (e *kindError) Unwrap() error { return e.err }
+
+func (e *kindError) Is(target error) bool { return target == e.kind }
@@ -0,0 +1,119 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjectErrors(t *testing.T) {
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	install := func(handler http.HandlerFunc, checksum string) error {
+		repo, closeProvider := newTestProviderHandler(t, handler)
+		defer closeProvider()
+
+		p := New("project", repo)
+		p.Dest = dest
+		p.Checksum = checksum
+		p.NoCache = true
+		return p.Install()
+	}
+
+	status := func(code int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(code)
+		}
+	}
+
+	archive := func(files ...testFile) http.HandlerFunc {
+		body := newTestArchive(t, files...)
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write(body)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		checksum string
+		expected error
+	}{
+		{"not found", status(http.StatusNotFound), "", ErrRepoNotFound},
+		{"unauthorized", status(http.StatusUnauthorized), "", ErrAccessDenied},
+		{"forbidden", status(http.StatusForbidden), "", ErrAccessDenied},
+		{"rate limited", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "1600000000")
+			w.WriteHeader(http.StatusForbidden)
+		}, "", ErrRateLimited},
+		{"checksum", archive(testFile{"project-master/go.mod", "module github.com/author/project\n"}), "0000", ErrChecksumMismatch},
+		{"not module", archive(testFile{"project-master/main.go", "package main\n"}), "", ErrNotModule},
+		{"illegal path", archive(
+			testFile{"project-master/../../evil.go", "package evil\n"},
+			testFile{"project-master/go.mod", "module github.com/author/project\n"},
+		), "", ErrIllegalPath},
+	}
+
+	for _, tt := range tests {
+		err := install(tt.handler, tt.checksum)
+		if !errors.Is(err, tt.expected) {
+			t.Fatalf("[%s] expected error: %v but got: %v", tt.name, tt.expected, err)
+		}
+
+		// A single "%w" verb is supported by the go version of the go.mod.
+		if strings.Contains(err.Error(), "%!") {
+			t.Fatalf("[%s] malformed error message: %v", tt.name, err)
+		}
+	}
+}
+
+func TestProjectErrorsFilesExist(t *testing.T) {
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	if err := ioutil.WriteFile(filepath.Join(dest, "main.go"), []byte("package old\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest}
+	err := p.unzip(context.Background(), newTestZip(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	if !errors.Is(err, ErrFilesExist) {
+		t.Fatalf("expected error: %v but got: %v", ErrFilesExist, err)
+	}
+}
+
+func TestProjectErrorsOffline(t *testing.T) {
+	p := New("project", "github.com/author/not-cached")
+	p.Dest = newTestDest(t)
+	defer os.RemoveAll(p.Dest)
+	p.Offline = true
+
+	if err := p.Install(); !errors.Is(err, ErrNotCached) {
+		t.Fatalf("expected error: %v but got: %v", ErrNotCached, err)
+	}
+}
+
+func TestProjectErrorsNetwork(t *testing.T) {
+	p := &Project{Name: "project", Repo: "author/project"}
+	err := p.downloadError(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})
+	if !errors.Is(err, ErrNetwork) {
+		t.Fatalf("expected error: %v but got: %v", ErrNetwork, err)
+	}
+
+	// The cause is wrapped too.
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Op != "dial" {
+		t.Fatalf("expected the network error to be wrapped but got: %v", err)
+	}
+}
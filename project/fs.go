@@ -0,0 +1,64 @@
+package project
+
+import (
+	"io"
+	"os"
+)
+
+// FS is the file system which the project's files are extracted into, see `Project.FS`.
+// The paths are the OS-specific ones of the destination, e.g. "/home/me/app/main.go".
+type FS interface {
+	// OpenFile opens a file for writing, like `os.OpenFile`.
+	OpenFile(name string, flag int, perm os.FileMode) (io.WriteCloser, error)
+	// MkdirAll creates a directory and its missing parents, like `os.MkdirAll`.
+	MkdirAll(path string, perm os.FileMode) error
+	// Chmod changes the permissions of a file or a directory, like `os.Chmod`.
+	Chmod(name string, mode os.FileMode) error
+	// Stat returns the information of a file or a directory, like `os.Stat`,
+	// an error which satisfies `os.IsNotExist` if it does not exist.
+	Stat(name string) (os.FileInfo, error)
+	// Remove removes a file or an empty directory, like `os.Remove`.
+	Remove(name string) error
+	// RemoveAll removes a directory and all of its files, like `os.RemoveAll`.
+	RemoveAll(path string) error
+	// Symlink creates a symbolic link "newname" to "oldname", like `os.Symlink`.
+	Symlink(oldname, newname string) error
+}
+
+// OSFS is the FS of the operating system, the default one.
+var OSFS FS = osFS{}
+
+type osFS struct{}
+
+func (osFS) OpenFile(name string, flag int, perm os.FileMode) (io.WriteCloser, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+func (osFS) MkdirAll(path string, perm os.FileMode) error { return os.MkdirAll(path, perm) }
+func (osFS) Chmod(name string, mode os.FileMode) error    { return os.Chmod(name, mode) }
+func (osFS) Stat(name string) (os.FileInfo, error)        { return os.Stat(name) }
+func (osFS) Remove(name string) error                     { return os.Remove(name) }
+func (osFS) RemoveAll(path string) error                  { return os.RemoveAll(path) }
+func (osFS) Symlink(oldname, newname string) error        { return os.Symlink(oldname, newname) }
+
+// fs returns the project's `FS` or the `OSFS`.
+func (p *Project) fs() FS {
+	if p.FS != nil {
+		return p.FS
+	}
+
+	return OSFS
+}
+
+// exists reports whether the "path" exists in the project's `FS`, see `utils.Exists`.
+func (p *Project) exists(path string) bool {
+	return fsExists(p.fs(), path)
+}
+
+func fsExists(fsys FS, path string) bool {
+	if _, err := fsys.Stat(path); err != nil && os.IsNotExist(err) {
+		return false
+	}
+
+	return true
+}
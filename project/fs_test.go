@@ -0,0 +1,187 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// memFS is an in-memory `FS`, the directories are the ones with a nil contents.
+type memFS struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	modes map[string]os.FileMode
+}
+
+func newMemFS() *memFS {
+	return &memFS{files: make(map[string][]byte), modes: make(map[string]os.FileMode)}
+}
+
+type memFile struct {
+	fs   *memFS
+	name string
+	buf  bytes.Buffer
+}
+
+func (f *memFile) Write(b []byte) (int, error) { return f.buf.Write(b) }
+
+func (f *memFile) Close() error {
+	f.fs.mu.Lock()
+	f.fs.files[f.name] = append([]byte{}, f.buf.Bytes()...)
+	f.fs.mu.Unlock()
+	return nil
+}
+
+func (fs *memFS) OpenFile(name string, flag int, perm os.FileMode) (io.WriteCloser, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, ok := fs.files[filepath.Dir(name)]; !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	fs.files[name] = []byte{}
+	fs.modes[name] = perm
+	return &memFile{fs: fs, name: name}, nil
+}
+
+func (fs *memFS) MkdirAll(path string, perm os.FileMode) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	for d := path; ; d = filepath.Dir(d) {
+		if _, ok := fs.files[d]; !ok {
+			fs.files[d] = nil
+			fs.modes[d] = os.ModeDir | perm
+		}
+
+		if filepath.Dir(d) == d {
+			return nil
+		}
+	}
+}
+
+func (fs *memFS) Chmod(name string, mode os.FileMode) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.modes[name] = fs.modes[name]&os.ModeType | mode
+	return nil
+}
+
+func (fs *memFS) Stat(name string) (os.FileInfo, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, ok := fs.files[name]; !ok {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	}
+
+	return memFileInfo{name: filepath.Base(name), size: int64(len(fs.files[name])), mode: fs.modes[name]}, nil
+}
+
+func (fs *memFS) Remove(name string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, ok := fs.files[name]; !ok {
+		return &os.PathError{Op: "remove", Path: name, Err: os.ErrNotExist}
+	}
+
+	delete(fs.files, name)
+	return nil
+}
+
+func (fs *memFS) RemoveAll(path string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	for name := range fs.files {
+		if name == path || strings.HasPrefix(name, path+string(os.PathSeparator)) {
+			delete(fs.files, name)
+		}
+	}
+
+	return nil
+}
+
+func (fs *memFS) Symlink(oldname, newname string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.files[newname] = []byte(oldname)
+	fs.modes[newname] = os.ModeSymlink | 0777
+	return nil
+}
+
+// regularFiles returns the sorted slash-separated paths of the regular files under the "dir".
+func (fs *memFS) regularFiles(dir string) (names []string) {
+	for name, mode := range fs.modes {
+		if _, ok := fs.files[name]; ok && mode.IsRegular() && strings.HasPrefix(name, dir+string(os.PathSeparator)) {
+			names = append(names, filepath.ToSlash(strings.TrimPrefix(name, dir+string(os.PathSeparator))))
+		}
+	}
+
+	sort.Strings(names)
+	return
+}
+
+type memFileInfo struct {
+	name string
+	size int64
+	mode os.FileMode
+}
+
+func (fi memFileInfo) Name() string       { return fi.name }
+func (fi memFileInfo) Size() int64        { return fi.size }
+func (fi memFileInfo) Mode() os.FileMode  { return fi.mode }
+func (fi memFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi memFileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi memFileInfo) Sys() interface{}   { return nil }
+
+func TestProjectUnzipFS(t *testing.T) {
+	dest := filepath.Join(os.TempDir(), "iris-cli-memfs", "app")
+	fs := newMemFS()
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, Module: "github.com/me/app", FS: fs}
+	err := p.unzip(context.Background(), newTestZip(t,
+		testFile{"project-master/main.go", "package main\n\nimport _ \"github.com/author/project/sub\"\n"},
+		testFile{"project-master/sub/sub.go", "package sub\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if utils.Exists(filepath.Dir(dest)) {
+		t.Fatalf("expected nothing to be written to the OS file system")
+	}
+
+	if expected, got := []string{"go.mod", "main.go", "sub/sub.go"}, fs.regularFiles(dest); strings.Join(expected, ",") != strings.Join(got, ",") {
+		t.Fatalf("expected files: %v but got: %v", expected, got)
+	}
+
+	if expected, got := "package main\n\nimport _ \"github.com/me/app/sub\"\n", string(fs.files[filepath.Join(dest, "main.go")]); expected != got {
+		t.Fatalf("expected contents:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	// The existing files of the FS are detected.
+	p = &Project{Name: "project", Repo: "author/project", Dest: dest, FS: fs}
+	err = p.unzip(context.Background(), newTestZip(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	if !errors.Is(err, ErrFilesExist) {
+		t.Fatalf("expected a files exist error but got: %v", err)
+	}
+}
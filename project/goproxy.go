@@ -0,0 +1,164 @@
+package project
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// DefaultGoProxy is the module proxy of `Project.GoProxy` when the GOPROXY environment variable is not set.
+const DefaultGoProxy = "https://proxy.golang.org"
+
+// goProxyURLs returns the module proxy URLs of the GOPROXY environment variable, in order,
+// without the "direct" entries. It returns nil if the proxies are disabled, e.g. GOPROXY=off.
+func goProxyURLs() []string {
+	value, ok := os.LookupEnv("GOPROXY")
+	if !ok || value == "" {
+		value = DefaultGoProxy
+	}
+
+	var proxies []string
+	for _, entry := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '|' }) {
+		switch entry = strings.TrimSpace(entry); entry {
+		case "", "direct":
+		case "off":
+			return proxies // the rest are not used.
+		default:
+			proxies = append(proxies, strings.TrimSuffix(entry, "/"))
+		}
+	}
+
+	return proxies
+}
+
+// goProxies returns the `goProxyURLs` or an error about the "module" if the GOPROXY environment variable disables them.
+func goProxies(module string) ([]string, error) {
+	proxies := goProxyURLs()
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("module <%s>: the GOPROXY environment variable disables the module proxies", module)
+	}
+
+	return proxies, nil
+}
+
+// goProxyProvider returns the provider which downloads the module zips from the "proxies",
+// the first one and, on failure, the second one. The "repo" of its URLs is a module path.
+func goProxyProvider(proxies []string) *Provider {
+	moduleURL := func(proxy, module, version string) string {
+		return proxy + "/" + escapeModulePath(module) + "/@v/" + escapeModulePath(version) + ".zip"
+	}
+
+	provider := &Provider{
+		ArchiveURL: func(module, version string) string {
+			return moduleURL(proxies[0], module, version)
+		},
+		Format: FormatZip,
+	}
+
+	if u, err := url.Parse(proxies[0]); err == nil {
+		provider.Host = u.Host
+	}
+
+	if len(proxies) > 1 {
+		provider.FallbackArchiveURL = func(module, version string) string {
+			return moduleURL(proxies[1], module, version)
+		}
+	}
+
+	return provider
+}
+
+// resolveProxyVersion sets the `Version` to the canonical version of the module which the proxy resolves it to,
+// e.g. "v1.2.3" for "v1.2", a branch or a commit, or the latest version for the default one.
+// A canonical version, e.g. "v1.2.3", is kept as it is, so it can be read from the cache.
+func (p *Project) resolveProxyVersion(ctx context.Context) error {
+	if p.Version != defaultVersion && utils.IsCanonicalVersion(p.Version) {
+		return nil
+	}
+
+	proxies, err := goProxies(p.Repo)
+	if err != nil {
+		return err
+	}
+
+	if p.Offline {
+		return fmt.Errorf("module <%s> version <%s> is %w, please use a canonical version", p.Repo, p.Version, ErrNotCached)
+	}
+
+	query := "/@v/" + escapeModulePath(p.Version) + ".info"
+	if p.Version == defaultVersion {
+		query = "/@latest"
+	}
+
+	for _, proxy := range proxies {
+		infoURL := proxy + "/" + escapeModulePath(p.Repo) + query
+
+		var b []byte
+		if b, err = utils.DownloadContext(ctx, p.httpClient(), infoURL, nil, p.downloadOptions(nil)...); err != nil {
+			p.logf("resolve <%s> failed: %v", infoURL, err)
+			continue
+		}
+
+		var info struct {
+			Version string
+		}
+		if err = json.Unmarshal(b, &info); err != nil {
+			return err
+		}
+
+		if info.Version == "" {
+			return fmt.Errorf("module <%s> version <%s> is not resolved by <%s>", p.Repo, p.Version, proxy)
+		}
+
+		p.logf("module <%s> version <%s> resolved to <%s>", p.Repo, p.Version, info.Version)
+		p.Version = info.Version
+		return nil
+	}
+
+	return p.downloadError(err)
+}
+
+// escapeModulePath escapes a module path or a version for a proxy URL,
+// each upper case letter becomes an exclamation mark followed by its lower case, e.g. "!azure" for "Azure".
+func escapeModulePath(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if 'A' <= r && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// moduleRootFolder returns the top-level folder which all "files" of a module zip share,
+// the module path and its version, e.g. "github.com/kataras/iris/v12@v12.1.8/".
+func moduleRootFolder(files []*zip.File) (string, error) {
+	var root string
+	for _, f := range files {
+		at := strings.IndexByte(f.Name, '@')
+		i := -1
+		if at >= 0 {
+			i = strings.IndexByte(f.Name[at:], '/')
+		}
+		if i == -1 {
+			return "", fmt.Errorf("expected a module@version root folder but got <%s>", f.Name)
+		}
+
+		if name := f.Name[:at+i+1]; root == "" {
+			root = name
+		} else if name != root {
+			return "", fmt.Errorf("expected a single root folder but got <%s> and <%s>", root, name)
+		}
+	}
+
+	return root, nil
+}
@@ -0,0 +1,85 @@
+package project
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectInstallGoProxy(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"example.com/Author/project@v1.1.0/go.mod", "module example.com/Author/project\n"},
+		testFile{"example.com/Author/project@v1.1.0/main.go", "package main\n\nimport _ \"example.com/Author/project/sub\"\n"},
+		testFile{"example.com/Author/project@v1.1.0/sub/sub.go", "package sub\n"},
+	)
+
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("expected no token to be sent to the module proxy")
+		}
+
+		switch r.URL.Path {
+		case "/example.com/!author/project/@latest", "/example.com/!author/project/@v/v1.1.info":
+			w.Write([]byte(`{"Version":"v1.1.0","Time":"2020-05-01T00:00:00Z"}`))
+		case "/example.com/!author/project/@v/v1.1.0.zip":
+			w.Write(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	for key, value := range map[string]string{"GOPROXY": srv.URL + ",direct", "IRIS_CLI_TOKEN": "secret"} {
+		defer os.Setenv(key, os.Getenv(key))
+		os.Setenv(key, value)
+	}
+
+	for _, version := range []string{"", "v1.1", "v1.1.0"} {
+		requests = nil
+
+		dest := newTestDest(t)
+		defer os.RemoveAll(dest)
+
+		p := New("project", "example.com/Author/project")
+		if version != "" {
+			p.Version = version
+		}
+		p.GoProxy = true
+		p.NoCache = true
+		p.Dest = dest
+		p.Module = "newproject"
+		installed, err := p.Installation(context.Background())
+		if err != nil {
+			t.Fatalf("[%s] %v", version, err)
+		}
+
+		if expected, got := "v1.1.0", installed.Version; expected != got {
+			t.Fatalf("[%s] expected version: %s but got: %s", version, expected, got)
+		}
+
+		expectFile(t, filepath.Join(dest, "go.mod"), "module newproject\n")
+		expectFile(t, filepath.Join(dest, "main.go"), "package main\n\nimport _ \"newproject/sub\"\n")
+		expectFile(t, filepath.Join(dest, "sub", "sub.go"), "package sub\n")
+
+		// A canonical version is not resolved.
+		expected := 2
+		if version == "v1.1.0" {
+			expected = 1
+		}
+		if len(requests) != expected {
+			t.Fatalf("[%s] expected %d proxy requests but got: %v", version, expected, requests)
+		}
+	}
+
+	os.Setenv("GOPROXY", "off")
+	p := New("project", "example.com/Author/project")
+	p.GoProxy = true
+	if err := p.Validate(); err == nil {
+		t.Fatalf("expected an error when the module proxies are disabled")
+	}
+}
@@ -0,0 +1,95 @@
+package project
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	localGoVersionOnce sync.Once
+	localGoVersionText string
+)
+
+// localGoVersion returns the version of the installed go toolchain, e.g. "1.14.2",
+// or an empty string if go is not installed. It can be replaced by tests.
+var localGoVersion = func() string {
+	localGoVersionOnce.Do(func() {
+		out, err := exec.Command("go", "version").Output()
+		if err != nil {
+			return
+		}
+
+		// go version go1.14.2 linux/amd64
+		if fields := strings.Fields(string(out)); len(fields) >= 3 {
+			localGoVersionText = strings.TrimPrefix(fields[2], "go")
+		}
+	})
+
+	return localGoVersionText
+}
+
+// checkGoVersion returns an error if the installed go toolchain is older than the "minGo" version.
+// It does nothing if go is not installed or its version can't be compared, e.g. a development version.
+func checkGoVersion(minGo string) error {
+	local := localGoVersion()
+	if minGo == "" || local == "" {
+		return nil
+	}
+
+	if cmp, ok := compareGoVersions(local, minGo); ok && cmp < 0 {
+		return fmt.Errorf("go version <%s> or newer is required but <%s> is installed", minGo, local)
+	}
+
+	return nil
+}
+
+// compareGoVersions returns -1, 0 or 1 when the "a" version is older, equal or newer than "b",
+// e.g. "1.13", "1.14.2" or "1.21rc1". It reports false if any of them is not a release version.
+func compareGoVersions(a, b string) (int, bool) {
+	va, ok := parseGoVersion(a)
+	if !ok {
+		return 0, false
+	}
+
+	vb, ok := parseGoVersion(b)
+	if !ok {
+		return 0, false
+	}
+
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+
+	return 0, true
+}
+
+// parseGoVersion returns the major, minor and patch numbers of a go "version",
+// the pre-release suffix, if any, is ignored.
+func parseGoVersion(version string) (v [3]int, ok bool) {
+	if i := strings.IndexAny(version, "abcdefghijklmnopqrstuvwxyz-+ "); i > 0 {
+		version = version[:i]
+	}
+
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return v, false
+	}
+
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return v, false
+		}
+		v[i] = n
+	}
+
+	return v, true
+}
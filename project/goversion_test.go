@@ -0,0 +1,59 @@
+package project
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCompareGoVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+		ok       bool
+	}{
+		{"1.14.2", "1.13", 1, true},
+		{"1.13", "1.13.0", 0, true},
+		{"1.12.17", "1.13", -1, true},
+		{"1.21rc1", "1.21", 0, true},
+		{"1.9", "1.13", -1, true},
+		{"devel", "1.13", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := compareGoVersions(tt.a, tt.b)
+		if tt.expected != got || tt.ok != ok {
+			t.Fatalf("[%s, %s] expected: %d (%v) but got: %d (%v)", tt.a, tt.b, tt.expected, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestProjectUnzipMinGo(t *testing.T) {
+	defer func(fn func() string) { localGoVersion = fn }(localGoVersion)
+	localGoVersion = func() string { return "1.14.2" }
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest}
+	err := p.unzip(context.Background(), newTestZip(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n\ngo 1.15\n"},
+	))
+	if err == nil || !strings.Contains(err.Error(), "1.15") {
+		t.Fatalf("expected a go version error but got: %v", err)
+	}
+
+	if expected, got := "1.15", p.MinGo; expected != got {
+		t.Fatalf("expected minimum go version: %s but got: %s", expected, got)
+	}
+
+	err = p.unzip(context.Background(), newTestZip(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n\ngo 1.13\n"},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
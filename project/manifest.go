@@ -0,0 +1,199 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// ManifestFilename is the name of the file which `Install` writes inside the project's root,
+// it records how the project was installed and marks the directory as created by iris-cli,
+// see `ReadManifest` and `Uninstall`.
+const ManifestFilename = ".iris-cli.json"
+
+// manifest is the contents of the `ManifestFilename`.
+type manifest struct {
+	Name            string            `json:"name,omitempty"`
+	Repo            string            `json:"repo"`
+	Version         string            `json:"version"`
+	GoProxy         bool              `json:"goProxy,omitempty"`
+	Subdir          string            `json:"subdir,omitempty"`
+	StripComponents int               `json:"stripComponents,omitempty"`
+	Include         []string          `json:"include,omitempty"`
+	Exclude         []string          `json:"exclude,omitempty"`
+	ExcludeCI       bool              `json:"excludeCI,omitempty"`
+	Vars            map[string]string `json:"vars,omitempty"`
+	Module          string            `json:"module"`
+	OldModule       string            `json:"oldModule,omitempty"`
+	ModuleDir       string            `json:"moduleDir,omitempty"`
+	Placeholders    []string          `json:"placeholders,omitempty"`
+	AppName         string            `json:"appName,omitempty"`
+	AppNameToken    string            `json:"appNameToken,omitempty"`
+	AppNameFiles    []string          `json:"appNameFiles,omitempty"`
+	Replaces        map[string]string `json:"replaces,omitempty"`
+	// Checksum is the SHA256 hex digest of the installed archive.
+	Checksum string `json:"checksum"`
+	// Files are the extracted files and their SHA256 hex digest, see `Project.Installed`.
+	Files map[string]string `json:"files"`
+}
+
+// writeManifest writes the project's manifest file inside its destination directory.
+// The "checksum" is the SHA256 hex digest of the installed archive.
+func (p *Project) writeManifest(checksum string) error {
+	m := manifest{
+		Name:            p.Name,
+		Repo:            p.Repo,
+		Version:         p.Version,
+		GoProxy:         p.GoProxy,
+		Subdir:          p.Subdir,
+		StripComponents: p.StripComponents,
+		Include:         p.Include,
+		Exclude:         p.Exclude,
+		ExcludeCI:       p.ExcludeCI,
+		Vars:            p.Vars,
+		Module:          p.Module,
+		OldModule:       p.OldModule,
+		ModuleDir:       p.ModuleDir,
+		Placeholders:    p.Placeholders,
+		AppName:         p.AppName,
+		AppNameToken:    p.AppNameToken,
+		AppNameFiles:    p.AppNameFiles,
+		Replaces:        p.Replaces,
+		Checksum:        checksum,
+		Files:           p.Installed,
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := p.fs().OpenFile(filepath.Join(p.Dest, ManifestFilename), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// ReadManifest returns the project installed inside the "dir" directory,
+// as it was recorded by `Install` to the `ManifestFilename`.
+// Its `Dest` is the "dir" and its `Checksum` is the one of the installed archive.
+func ReadManifest(dir string) (*Project, error) {
+	dir = utils.Dest(dir)
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, ManifestFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("directory <%s> is not a project installed by iris-cli", dir)
+		}
+		return nil, err
+	}
+
+	var m manifest
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("manifest <%s>: %w", filepath.Join(dir, ManifestFilename), err)
+	}
+
+	return &Project{
+		Name:            m.Name,
+		Repo:            m.Repo,
+		Version:         m.Version,
+		GoProxy:         m.GoProxy,
+		Checksum:        m.Checksum,
+		Subdir:          m.Subdir,
+		StripComponents: m.StripComponents,
+		Include:         m.Include,
+		Exclude:         m.Exclude,
+		ExcludeCI:       m.ExcludeCI,
+		Vars:            m.Vars,
+		Dest:            dir,
+		Module:          m.Module,
+		OldModule:       m.OldModule,
+		ModuleDir:       m.ModuleDir,
+		Placeholders:    m.Placeholders,
+		AppName:         m.AppName,
+		AppNameToken:    m.AppNameToken,
+		AppNameFiles:    m.AppNameFiles,
+		Replaces:        m.Replaces,
+		Installed:       m.Files,
+	}, nil
+}
+
+// Uninstall removes the files of the project which were installed into its destination directory,
+// as they are recorded by the `ManifestFilename`, and the manifest itself. Then the directories which are left empty
+// are removed too, so the user's own files of the destination, e.g. of the current directory, are kept.
+// It refuses to remove anything from a directory which does not contain the `ManifestFilename`.
+func (p *Project) Uninstall() error {
+	dest := resolveDest(p.Dest, p.Module, p.GOPATH)
+
+	installed, err := ReadManifest(dest)
+	if err != nil {
+		return err
+	}
+
+	dirs := make(map[string]struct{})
+	for name := range installed.Installed {
+		fpath := filepath.Join(dest, filepath.FromSlash(name))
+		if !strings.HasPrefix(fpath, dest+string(os.PathSeparator)) {
+			return fmt.Errorf("%w: %s", ErrIllegalPath, fpath)
+		}
+
+		if err = os.Remove(fpath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		for dir := filepath.Dir(fpath); dir != dest; dir = filepath.Dir(dir) {
+			dirs[dir] = struct{}{}
+		}
+	}
+
+	if err = os.Remove(filepath.Join(dest, ManifestFilename)); err != nil {
+		return err
+	}
+
+	// Remove the deepest directories first, a directory which is not empty is kept.
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, dir := range sorted {
+		os.Remove(dir)
+	}
+
+	if isRemovableDir(dest) {
+		os.Remove(dest)
+	}
+
+	return nil
+}
+
+// isRemovableDir reports whether the empty "dir" can be removed after an uninstallation,
+// it's not the root, the home or the current working directory.
+func isRemovableDir(dir string) bool {
+	if filepath.Dir(dir) == dir {
+		return false
+	}
+
+	if home, err := os.UserHomeDir(); err == nil && filepath.Clean(home) == dir {
+		return false
+	}
+
+	if wd, err := os.Getwd(); err == nil && filepath.Clean(wd) == dir {
+		return false
+	}
+
+	return true
+}
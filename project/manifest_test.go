@@ -0,0 +1,151 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+func TestProjectUninstall(t *testing.T) {
+	repo, closeProvider := newTestProvider(t, newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	defer closeProvider()
+
+	parent := newTestDest(t)
+	defer os.RemoveAll(parent)
+
+	dest := filepath.Join(parent, "project")
+	p := New("project", repo)
+	p.Dest = dest
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !utils.Exists(filepath.Join(dest, ManifestFilename)) {
+		t.Fatalf("expected the manifest file to be written")
+	}
+
+	if err := p.Uninstall(); err != nil {
+		t.Fatal(err)
+	}
+
+	if utils.Exists(dest) {
+		t.Fatalf("expected the project directory to be removed")
+	}
+
+	other := &Project{Dest: parent}
+	if err := other.Uninstall(); err == nil || !strings.Contains(err.Error(), "not a project") {
+		t.Fatalf("expected an error for a directory without manifest but got: %v", err)
+	}
+
+	if !utils.Exists(parent) {
+		t.Fatalf("expected a directory without manifest to not be removed")
+	}
+}
+
+func TestProjectUninstallExistingDest(t *testing.T) {
+	repo, closeProvider := newTestProvider(t, newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/sub/sub.go", "package sub\n"},
+		testFile{"project-master/notes/todo.md", "# TODO\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	// The user's own files of an existing destination, e.g. of "new .".
+	for _, name := range []string{"mine.txt", filepath.Join("notes", "mine.md")} {
+		fpath := filepath.Join(dest, name)
+		if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fpath, []byte("mine\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := New("project", repo)
+	p.Dest = dest
+	p.Overwrite = OverwriteSkip
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Uninstall(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(dest, "mine.txt"), "mine\n")
+	expectFile(t, filepath.Join(dest, "notes", "mine.md"), "mine\n")
+
+	for _, name := range []string{ManifestFilename, "main.go", "go.mod", "sub", filepath.Join("notes", "todo.md")} {
+		if utils.Exists(filepath.Join(dest, name)) {
+			t.Fatalf("expected <%s> to be removed", name)
+		}
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n\nimport _ \"github.com/author/project/sub\"\n"},
+		testFile{"project-master/README.md", "# Project\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	)
+	repo, closeProvider := newTestProvider(t, body)
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := New("project", repo)
+	p.Dest = dest
+	p.Module = "newproject"
+	p.Exclude = []string{"*.md"}
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadManifest(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checksum := sha256.Sum256(body)
+	expected := &Project{
+		Name:     "project",
+		Repo:     repo,
+		Version:  "master",
+		Checksum: hex.EncodeToString(checksum[:]),
+		Exclude:  []string{"*.md"},
+		Dest:     dest,
+		Module:   "newproject",
+		Installed: map[string]string{
+			"go.mod":  sha256Hex("module newproject\n"),
+			"main.go": sha256Hex("package main\n\nimport _ \"newproject/sub\"\n"),
+		},
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected manifest:\n%#+v\nbut got:\n%#+v", expected, got)
+	}
+
+	if _, err = ReadManifest(filepath.Join(dest, "missing")); err == nil {
+		t.Fatalf("expected an error for a directory without manifest")
+	}
+}
+
+func sha256Hex(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
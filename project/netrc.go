@@ -0,0 +1,144 @@
+package project
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// netrcLine is a "machine" entry of a .netrc file, an empty machine is the "default" entry.
+type netrcLine struct {
+	machine  string
+	login    string
+	password string
+}
+
+// netrcFile returns the path of the user's .netrc file,
+// the NETRC environment variable or the $HOME/.netrc ($HOME/_netrc on windows).
+func netrcFile() string {
+	if name := os.Getenv("NETRC"); name != "" {
+		return name
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	name := ".netrc"
+	if runtime.GOOS == "windows" {
+		name = "_netrc"
+	}
+
+	return filepath.Join(home, name)
+}
+
+// readNetrc reads the machine entries of the "filename" .netrc file.
+// It returns nothing if the file does not exist or if it can be accessed by other users,
+// as the go command and git (curl) do, the credentials should not be shared.
+func readNetrc(filename string) []netrcLine {
+	if filename == "" {
+		return nil
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+
+	return parseNetrc(string(b))
+}
+
+// parseNetrc parses the "data" of a .netrc file, the "account"s and the "macdef" definitions are skipped.
+func parseNetrc(data string) (lines []netrcLine) {
+	var (
+		l       *netrcLine
+		inMacro bool
+	)
+
+	for _, line := range strings.Split(data, "\n") {
+		if inMacro {
+			// A macro definition ends on a blank line.
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+
+		f := strings.Fields(line)
+		for i := 0; i < len(f); i++ {
+			switch f[i] {
+			case "default":
+				lines = append(lines, netrcLine{})
+				l = &lines[len(lines)-1]
+				continue // it has no value.
+			case "macdef":
+				inMacro = true
+			}
+
+			if i++; i == len(f) {
+				break
+			}
+
+			switch f[i-1] {
+			case "machine":
+				lines = append(lines, netrcLine{machine: f[i]})
+				l = &lines[len(lines)-1]
+			case "login":
+				if l != nil {
+					l.login = f[i]
+				}
+			case "password":
+				if l != nil {
+					l.password = f[i]
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// netrcCredentials returns the login and password of the "host" from the "lines",
+// the "default" entry is used when there is no machine entry of the "host".
+func netrcCredentials(lines []netrcLine, host string) (string, string, bool) {
+	for _, l := range lines {
+		if l.machine == host || l.machine == "" {
+			if l.login == "" && l.password == "" {
+				return "", "", false
+			}
+
+			return l.login, l.password, true
+		}
+	}
+
+	return "", "", false
+}
+
+// netrcAuth returns a download option which authorizes the requests with the basic auth
+// credentials of the request URL's host, if the user's .netrc file contains it.
+func netrcAuth() utils.DownloadOption {
+	return func(r *http.Request) error {
+		if r.Header.Get("Authorization") != "" {
+			return nil
+		}
+
+		if login, password, ok := netrcCredentials(readNetrc(netrcFile()), r.URL.Hostname()); ok {
+			r.SetBasicAuth(login, password)
+		}
+
+		return nil
+	}
+}
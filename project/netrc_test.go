@@ -0,0 +1,124 @@
+package project
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestParseNetrc(t *testing.T) {
+	data := `
+machine github.com login user password secret
+# a comment
+machine gitlab.com
+	login other
+	account ignored
+	password pass
+
+macdef init
+	machine fake.com login fake password fake
+
+default login anonymous password guest
+`
+	expected := []netrcLine{
+		{machine: "github.com", login: "user", password: "secret"},
+		{machine: "gitlab.com", login: "other", password: "pass"},
+		{login: "anonymous", password: "guest"},
+	}
+	lines := parseNetrc(data)
+	if !reflect.DeepEqual(expected, lines) {
+		t.Fatalf("expected lines:\n%#+v\nbut got:\n%#+v", expected, lines)
+	}
+
+	var tests = []struct {
+		host            string
+		login, password string
+	}{
+		{"github.com", "user", "secret"},
+		{"gitlab.com", "other", "pass"},
+		{"bitbucket.org", "anonymous", "guest"},
+	}
+
+	for i, tt := range tests {
+		login, password, ok := netrcCredentials(lines, tt.host)
+		if !ok || login != tt.login || password != tt.password {
+			t.Fatalf("[%d] expected credentials of <%s>: %s:%s but got: %s:%s", i, tt.host, tt.login, tt.password, login, password)
+		}
+	}
+
+	if _, _, ok := netrcCredentials(lines[:2], "bitbucket.org"); ok {
+		t.Fatalf("expected no credentials without a default entry")
+	}
+}
+
+func TestProjectDownloadNetrc(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	)
+
+	var login, password string
+	repo, closeProvider := newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		login, password, _ = r.BasicAuth()
+		w.Write(body)
+	})
+	defer closeProvider()
+
+	dir, err := ioutil.TempDir("", "iris-cli-netrc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	netrc := filepath.Join(dir, ".netrc")
+	if err = ioutil.WriteFile(netrc, []byte("machine 127.0.0.1 login user password secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("NETRC", netrc)
+	defer os.Unsetenv("NETRC")
+
+	install := func(token string) {
+		t.Helper()
+
+		dest := newTestDest(t)
+		defer os.RemoveAll(dest)
+
+		login, password = "", ""
+		p := New("project", repo)
+		p.Dest = dest
+		p.NoCache = true
+		p.Token = token
+		if err := p.Install(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	install("")
+	if login != "user" || password != "secret" {
+		t.Fatalf("expected the .netrc credentials but got: %s:%s", login, password)
+	}
+
+	// The token has priority.
+	install("token")
+	if login != "" || password != "" {
+		t.Fatalf("expected no .netrc credentials when a token is set but got: %s:%s", login, password)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	// Ignored if other users can read it.
+	if err = os.Chmod(netrc, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	install("")
+	if login != "" || password != "" {
+		t.Fatalf("expected no .netrc credentials when its permissions are too open but got: %s:%s", login, password)
+	}
+}
@@ -2,31 +2,222 @@ package project
 
 import (
 	"archive/zip"
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"go/build"
+	"go/format"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/kataras/iris-cli/utils"
 )
 
+// OverwritePolicy describes how the existing files of a project's destination are handled.
+type OverwritePolicy string
+
+const (
+	// OverwriteFail fails the installation before writing anything if a file already exists.
+	// This is the default policy.
+	OverwriteFail OverwritePolicy = "fail"
+	// OverwriteSkip keeps the existing files.
+	OverwriteSkip OverwritePolicy = "skip"
+	// OverwriteForce overwrites the existing files.
+	OverwriteForce OverwritePolicy = "force"
+)
+
 type Project struct {
 	Name string `json:"name,omitempty" yaml:"Name" toml:"Name"` // e.g. starter-kit
+	// Description is a short description of the project, e.g. as listed by `Templates`.
+	Description string `json:"description,omitempty" yaml:"Description" toml:"Description"`
 	// Remote.
-	Repo    string `json:"repo" yaml:"Repo" toml:"Repo"`                    // e.g. "iris-contrib/project1"
+	Repo string `json:"repo" yaml:"Repo" toml:"Repo"` // e.g. "iris-contrib/project1", "gitlab.com/group/project1", see `Provider`
+	// Version is the git reference to download: a branch, a tag (e.g. "v1.2.3") or a commit SHA.
+	// The archive's root folder is resolved from its contents, so all forms are supported.
 	Version string `json:"version,omitempty" yaml:"Version" toml:"Version"` // if empty then set to "master"
+	// DefaultBranch, if true, downloads the repository's default branch, as reported by its provider's API
+	// (e.g. GitHub's "default_branch"), when the `Version` is empty or "master", instead of guessing it.
+	// The lookup is cached like the archives. If it fails then the "master" and "main" branches are tried.
+	DefaultBranch bool `json:"-" yaml:"-" toml:"-"`
+	// GoProxy, if true, downloads the module zip of the `Repo`, a full module path (e.g. "github.com/kataras/iris/v12"),
+	// from the module proxies of the GOPROXY environment variable (defaults to `DefaultGoProxy`) instead of the repository's host,
+	// e.g. for networks which permit only the Go module proxy. The `Version` is resolved by the proxy
+	// to a canonical one, e.g. "v1.2" or a branch to "v1.2.3", and the default version to the latest one.
+	// The module zips contain the module's files only, e.g. without nested modules and VCS metadata.
+	GoProxy bool `json:"goProxy,omitempty" yaml:"GoProxy" toml:"GoProxy"`
+	// Archive, if not empty, is a local zip, tar or tar.gz file to install instead of downloading the repository's one,
+	// its entries should be inside a root folder, like the repository archives. See `NewFromArchive`.
+	Archive string `json:"-" yaml:"-" toml:"-"`
+	// Dir, if not empty, is a local directory to install instead of downloading the repository. See `NewFromDir`.
+	Dir string `json:"-" yaml:"-" toml:"-"`
+	// Checksum, if not empty, is the expected SHA256 hex digest of the downloaded archive.
+	Checksum string `json:"checksum,omitempty" yaml:"Checksum" toml:"Checksum"`
+	// Password, if not empty, decrypts the entries of a password-protected zip archive (ZipCrypto or AES),
+	// e.g. of templates which are distributed as secured artifacts. The archive is decrypted to a temporary
+	// zip file before the extraction. If empty then the archive is read as it is and encrypted entries fail.
+	Password string `json:"-" yaml:"-" toml:"-"`
+	// Token is used to download private repositories,
+	// if empty then the IRIS_CLI_TOKEN or, for github.com only, the GITHUB_TOKEN environment variable is used instead
+	// and, if none of them is set, the credentials of the host in the user's .netrc file, see `netrcFile`.
+	Token string `json:"-" yaml:"-" toml:"-"`
+	// Client is the http client which downloads the archive, e.g. with a custom transport or TLS configuration.
+	// If nil then a client which respects the HTTP_PROXY and HTTPS_PROXY environment variables
+	// and has a `DefaultTimeout` is used instead. Note that the GOPROXY environment variable is not related to it.
+	Client *http.Client `json:"-" yaml:"-" toml:"-"`
+	// Timeout, if positive, is the time limit of a download attempt, including the reading of the archive.
+	// It overrides the timeout of the `Client` and the `DefaultTimeout`.
+	Timeout time.Duration `json:"-" yaml:"-" toml:"-"`
+	// Retries is the number of download retries on network errors and 5xx or 429 responses,
+	// if zero then it's set to `DefaultRetries` and a negative value disables retries.
+	Retries int `json:"-" yaml:"-" toml:"-"`
+	// NoCache, if true, always downloads the archive instead of using the cached one.
+	NoCache bool `json:"-" yaml:"-" toml:"-"`
+	// Offline, if true, uses only the cached archive, even if its `CacheTTL` is expired.
+	Offline bool `json:"-" yaml:"-" toml:"-"`
+	// CacheTTL is the duration which a cached archive is used for, if zero then it's set to `DefaultCacheTTL`.
+	// When expired, the archive is downloaded again only if the server reports that it's modified (ETag).
+	CacheTTL time.Duration `json:"-" yaml:"-" toml:"-"`
+	// Subdir, if not empty, extracts only the files of this subdirectory of the repository, e.g. "rest-api",
+	// useful for repositories which contain many projects. Its go.mod is used as the module's one.
+	Subdir string `json:"subdir,omitempty" yaml:"Subdir" toml:"Subdir"`
+	// StripComponents removes this number of leading path elements of the archive's files, after its root folder,
+	// like tar's --strip-components, e.g. 1 for archives with an extra wrapper directory.
+	// The files with fewer path elements are skipped. The `Subdir` is relative to the stripped paths.
+	StripComponents int `json:"stripComponents,omitempty" yaml:"StripComponents" toml:"StripComponents"`
+	// Include, if not empty, extracts only the files which match any of these patterns.
+	// Exclude does not extract the files which match any of these patterns, it wins over the Include.
+	// The patterns are matched, see `path.Match`, against a file's path relative to the project's root
+	// and each of its parent directories, e.g. "docs" or ".github/*" match a whole folder.
+	// A pattern without a slash is matched against the file's name too, e.g. "*.md".
+	// Recursive "**" patterns are not supported.
+	Include []string `json:"include,omitempty" yaml:"Include" toml:"Include"`
+	Exclude []string `json:"exclude,omitempty" yaml:"Exclude" toml:"Exclude"`
+	// ExcludeCI, if true, does not extract the upstream's CI and VCS metadata, see `DefaultCIExcludes`,
+	// so the new project does not inherit the template's CI, e.g. its GitHub workflows.
+	ExcludeCI bool `json:"excludeCI,omitempty" yaml:"ExcludeCI" toml:"ExcludeCI"`
+	// OldModule, if not empty, is the import path which is rewritten to the `Module` inside the go source files,
+	// instead of the module name detected by the project's go.mod. It takes precedence over the detected one
+	// and the rewrite happens even if the detected module name is equal to the `Module`.
+	OldModule string `json:"oldModule,omitempty" yaml:"OldModule" toml:"OldModule"`
+	// ModuleDir is the slash-separated directory of the project's go.mod, relative to the project's root,
+	// e.g. "backend" for a project which contains a frontend at its root. If empty then the root's go.mod
+	// is used or, if it's missing, the one closest to the root, and the field is set to its directory.
+	// The module name is detected from this go.mod and it's replaced inside the go files of the whole project,
+	// the destination is still the project's root, e.g. "./app/backend/go.mod".
+	ModuleDir string `json:"moduleDir,omitempty" yaml:"ModuleDir" toml:"ModuleDir"`
+	// Placeholders, if not empty, are import paths which are rewritten to the `Module` inside the go source files,
+	// in addition to the detected (or the `OldModule`) one, e.g. "yourapp" or "example.com/changeme"
+	// of templates which are written to be edited. Like the module name, other files are not touched.
+	Placeholders []string `json:"placeholders,omitempty" yaml:"Placeholders" toml:"Placeholders"`
+	// AppName, if not empty, replaces the project's app name token, e.g. the binary name, inside the files
+	// which match the `AppNameFiles` patterns, e.g. "myapp" for a "FROM ... AS app" or "./app" of a Dockerfile.
+	// The token is the `AppNameToken`, or the "appName" of the project's `ConfigFilename`,
+	// or the last path element of the old module, e.g. "app" for "github.com/author/app/v2".
+	// It is replaced as a whole word only, e.g. "app-server" becomes "myapp-server" but "application" is kept.
+	//
+	// It does not conflict with the module rename: the go source files, the go.mod and the template files
+	// are never matched, they are handled by the `Module` and the `Vars`. However, a module path inside a matched file,
+	// e.g. "github.com/author/app" of a Dockerfile, contains the token as a word and it is renamed too.
+	AppName string `json:"appName,omitempty" yaml:"AppName" toml:"AppName"`
+	// AppNameToken, if not empty, overrides the detected token which is replaced with the `AppName`.
+	AppNameToken string `json:"appNameToken,omitempty" yaml:"AppNameToken" toml:"AppNameToken"`
+	// AppNameFiles are the patterns of the files which the `AppName` is replaced inside, like the `Include` ones.
+	// If empty then it's set to `DefaultAppNameFiles`.
+	AppNameFiles []string `json:"appNameFiles,omitempty" yaml:"AppNameFiles" toml:"AppNameFiles"`
+	// MinGo is set to the project of `Installation` to the go version of the project's go.mod directive, e.g. "1.14".
+	// The installation fails before writing any file if the installed go is older than that.
+	MinGo string `json:"-" yaml:"-" toml:"-"`
 	// Local.
-	Dest   string `json:"dest,omitempty" yaml:"Dest" toml:"Dest"`       // if empty then $GOPATH+Module or ./+Module
-	Module string `json:"module,omitempty" yaml:"Module" toml:"Module"` // if empty then set to the remote module name fetched from go.mod
+	// Dest is the directory which the project's files are extracted into, without a subfolder,
+	// e.g. "." for the current directory, its existing files are kept. See `Overwrite` too.
+	Dest   string `json:"dest,omitempty" yaml:"Dest" toml:"Dest"`       // if empty then ./+Module's name or $GOPATH/src/+Module, see `GOPATH`
+	Module string `json:"module,omitempty" yaml:"Module" toml:"Module"` // if empty then set to the remote module name fetched from go.mod, required without a go.mod
+	// GOPATH, if true, resolves an empty `Dest` to the $GOPATH/src/$module directory, the legacy GOPATH mode layout,
+	// instead of the ./$name directory, see `resolveDest`.
+	GOPATH bool `json:"-" yaml:"-" toml:"-"`
+	// Vars, if not empty, is the data of the project's template files, the files which end with ".tmpl".
+	// They are executed through the `text/template` package and saved without the ".tmpl" suffix,
+	// e.g. "README.md.tmpl" with "# {{.AppName}}" contents. A missing key is an error.
+	// If empty then the template files are extracted as they are.
+	// The template files are excluded from the module name replacement.
+	Vars map[string]string `json:"vars,omitempty" yaml:"Vars" toml:"Vars"`
+	// Format, if true, formats the go source files which are rewritten, because of a different module name
+	// or because they are templates, see `go/format`. Keep it false for byte-for-byte fidelity with upstream.
+	Format bool `json:"format,omitempty" yaml:"Format" toml:"Format"`
+	// MaxSize is the limit of the total uncompressed size of the extracted files, in bytes,
+	// as declared by the archive's entries. If zero then it's set to `DefaultMaxSize`
+	// and a negative value disables the limit. It protects against zip bombs.
+	MaxSize int64 `json:"-" yaml:"-" toml:"-"`
+	// FS, if not nil, is the file system which the project's files and its manifest are written into,
+	// e.g. an in-memory one for tests or a virtual destination. Defaults to the `OSFS`.
+	// The post installation steps, e.g. `Replaces`, `Tidy` and `Gitignore`, work on the OS file system only.
+	FS FS `json:"-" yaml:"-" toml:"-"`
+	// Overwrite is the policy for the existing files of the destination, defaults to `OverwriteFail`.
+	Overwrite OverwritePolicy `json:"-" yaml:"-" toml:"-"`
+	// DryRun, if true, does not write any file, the files which would be extracted are reported to `Preview` instead.
+	DryRun bool `json:"-" yaml:"-" toml:"-"`
+	// Preview reports a file which would be extracted on `DryRun`, "exists" is true if it's going to be overwritten.
+	Preview func(path string, exists bool) `json:"-" yaml:"-" toml:"-"`
+	// Rewrites, if not nil, reports on `DryRun` each file which contains the old module name or a placeholder,
+	// the number of their occurrences and whether the file would be rewritten (a go source file or the go.mod),
+	// before any file is written. The rest of the files are kept as they are, e.g. a README which mentions the module.
+	Rewrites func(path string, occurrences int, rewritten bool) `json:"-" yaml:"-" toml:"-"`
+	// KeepOnError, if true, keeps the partially extracted files on a failed installation, useful for debugging.
+	KeepOnError bool `json:"-" yaml:"-" toml:"-"`
+	// Logf, if not nil, logs the steps of the installation, e.g. the download URL,
+	// the destination, the module name and each extracted file. Silent by default.
+	Logf func(format string, args ...interface{}) `json:"-" yaml:"-" toml:"-"`
 
 	// Pre Installation.
+	// Reader, if not nil, reads the whole archive instead of streaming it to a temporary file.
 	Reader func(io.Reader) ([]byte, error) `json:"-" yaml:"-" toml:"-"`
+	// Progress, if not nil, reports the downloaded bytes of the archive.
+	// The "total" is -1 when the length of the archive is unknown
+	// and it's equal to "current" when the download is completed.
+	Progress func(current, total int64) `json:"-" yaml:"-" toml:"-"`
+	// ExtractProgress, if not nil, reports the number of the extracted archive entries.
+	// The files are extracted concurrently but it is never called concurrently.
+	ExtractProgress func(current, total int) `json:"-" yaml:"-" toml:"-"`
 	// Post Installation.
-	// InstalledPath string `json:"-" yaml:"-" toml:"-"` // the dest + name filepath if installed, if empty then it is not installed yet.
+	// PostInstall is set to the project of `Installation` to the commands of the project's `ConfigFilename`, if any.
+	// They run inside the destination directory, after the extraction, only if the `Confirm` allows them.
+	PostInstall []string `json:"-" yaml:"-" toml:"-"`
+	// Confirm, if not nil, is called with the `PostInstall` commands before running them
+	// and they run only if it returns true. If nil then they never run, as they are arbitrary commands.
+	Confirm func(commands []string) bool `json:"-" yaml:"-" toml:"-"`
+	// Replaces, if not empty, sets the replace directives of the project's go.mod after the extraction, e.g.
+	// {"github.com/kataras/iris/v12": "../iris"}, to point it to a local fork or an internal module mirror.
+	// A key may contain a version, e.g. "github.com/kataras/iris/v12 v12.1.8", and a value is a directory
+	// or a module path and a version. The existing directives are updated, see `utils.SetGoModReplace`.
+	Replaces map[string]string `json:"replaces,omitempty" yaml:"Replaces" toml:"Replaces"`
+	// Tidy, if true, runs "go mod tidy" inside the module's directory to fetch the dependencies, see `ModuleDir`.
+	Tidy bool `json:"-" yaml:"-" toml:"-"`
+	// Build, if true, verifies that the project compiles after the installation, see the `Build` function.
+	// It runs after the `Tidy`, a failure is a `*BuildError` but the project's files are kept.
+	Build bool `json:"-" yaml:"-" toml:"-"`
+	// Gitignore, if true, writes a .gitignore file for Go projects, if the project does not contain one.
+	Gitignore bool `json:"-" yaml:"-" toml:"-"`
+	// GitInit, if true, initializes a git repository inside the destination directory with an initial commit.
+	// It's skipped if git is not installed.
+	GitInit bool `json:"-" yaml:"-" toml:"-"`
+	// Installed is set to the project of `Installation` and by `ReadManifest`, it contains the extracted files,
+	// by their slash-separated path relative to the destination, and their SHA256 hex digest.
+	// The digest of a symbolic link is empty. See `InstalledFiles` too.
+	Installed map[string]string `json:"-" yaml:"-" toml:"-"`
 }
 
 func New(name, repo string) *Project {
@@ -47,6 +238,33 @@ func New(name, repo string) *Project {
 	}
 }
 
+// NewFromArchive returns a project which is installed from the local "zipPath" archive to "dest",
+// e.g. an archive of a repository which was downloaded before. See `Project.Archive`.
+func NewFromArchive(dest, zipPath string) *Project {
+	return &Project{
+		Name:    archiveName(zipPath),
+		Archive: zipPath,
+		Dest:    dest,
+	}
+}
+
+// archiveName returns the filename of "archivePath" without its extension, e.g. "starter" for "starter.tar.gz".
+func archiveName(archivePath string) string {
+	name := filepath.Base(archivePath)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	return strings.TrimSuffix(name, ".tar")
+}
+
+// NewFromDir returns a project which is installed by copying the local "dir" directory to "dest".
+// Its module name is replaced like the remote projects' one. See `Project.Dir`.
+func NewFromDir(dest, dir string) *Project {
+	return &Project{
+		Name: filepath.Base(filepath.Clean(dir)),
+		Dir:  dir,
+		Dest: dest,
+	}
+}
+
 func Run(projectPath string, stdOut, stdErr io.Writer) error {
 	goRun := exec.Command("go", "run", ".")
 	goRun.Dir = projectPath
@@ -55,151 +273,1405 @@ func Run(projectPath string, stdOut, stdErr io.Writer) error {
 	return goRun.Run()
 }
 
+// Install downloads and extracts the project. The "p" is not modified, see `Installation`.
 func (p *Project) Install() error {
-	b, err := p.download()
+	return p.InstallContext(context.Background())
+}
+
+// InstallContext same as `Install` but it accepts a context which can cancel
+// the download and the extraction of the project, e.g. on a timeout or on CTRL/CMD+C.
+// A canceled installation removes its temporary files and the files and directories it created
+// inside the destination, even after the extraction, e.g. while running the `Tidy`. See `KeepOnError` too.
+func (p *Project) InstallContext(ctx context.Context) error {
+	_, err := p.Installation(ctx)
+	return err
+}
+
+// Installation same as `InstallContext` but it returns the installed project, a copy of "p"
+// with the values which are resolved on installation. The "p" itself is never modified,
+// so it can be used as a template for many, even concurrent, installations, e.g. to different destinations.
+//
+// The resolved values of the returned project are:
+//   - Repo and Version, normalized, e.g. the "main" branch when there is no "master" one
+//   - Dest, the absolute directory of the project
+//   - Module and ModuleDir, the module path of the project's go.mod and its directory
+//   - MinGo, PostInstall and Installed, see their fields.
+func (p *Project) Installation(ctx context.Context) (*Project, error) {
+	installed := *p
+	if _, err := installed.install(ctx); err != nil {
+		return nil, err
+	}
+
+	return &installed, nil
+}
+
+// InstallResult is the result of an installation, see `Project.InstallResult`.
+type InstallResult struct {
+	// ResolvedModule is the module path of the installed project, the `Module` or the go.mod's one.
+	ResolvedModule string
+	// ResolvedDest is the absolute directory which the project is installed into.
+	ResolvedDest string
+	// ProjectDir is the absolute directory of the project's go.mod, the `ResolvedDest`
+	// or its `ModuleDir` subdirectory, e.g. to run the go commands inside it.
+	ProjectDir string
+	// Files are the sorted absolute paths of the written files, empty on `DryRun`.
+	Files []string
+	// ArchiveChecksum is the SHA256 hex digest of the installed archive, see `Checksum`.
+	ArchiveChecksum string
+}
+
+// InstallResult same as `InstallResultContext` with a background context.
+func (p *Project) InstallResult() (*InstallResult, error) {
+	return p.InstallResultContext(context.Background())
+}
+
+// InstallResultContext same as `InstallContext` but it returns the resolved values of the installation.
+// The "p" is not modified, see `Installation` for the installed project itself.
+func (p *Project) InstallResultContext(ctx context.Context) (*InstallResult, error) {
+	installed := *p
+	checksum, err := installed.install(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &InstallResult{
+		ResolvedModule:  installed.Module,
+		ResolvedDest:    installed.Dest,
+		ProjectDir:      filepath.Join(installed.Dest, filepath.FromSlash(installed.ModuleDir)),
+		Files:           installed.InstalledFiles(),
+		ArchiveChecksum: checksum,
+	}
+
+	return result, nil
+}
+
+// install downloads and extracts the project, it sets the resolved values to "p"
+// and returns the checksum of the archive.
+func (p *Project) install(ctx context.Context) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+
+	r, checksum, release, err := p.open(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	return checksum, p.installArchive(ctx, r, checksum)
+}
+
+// installArchive extracts the "r" archive and runs the post installation steps, e.g. `Tidy`.
+func (p *Project) installArchive(ctx context.Context, r *zip.Reader, checksum string) (err error) {
+	created := &rollback{fs: p.fs()}
+	if err = p.unzipTracked(ctx, r, created); err != nil || p.DryRun {
+		return err
+	}
+
+	// A canceled installation, e.g. on an interrupt signal, does not leave a partially installed project behind,
+	// the extracted files are removed too. The other failures of the post installation steps keep them.
+	defer func() {
+		if err != nil && ctx.Err() != nil && !p.KeepOnError {
+			p.logf("installation canceled, remove the created files of <%s>", p.Dest)
+			created.undo()
+		}
+	}()
+
+	if len(p.Replaces) > 0 {
+		if err = p.setReplaces(); err != nil {
+			return err
+		}
+	}
+
+	created.track(filepath.Join(p.Dest, ManifestFilename))
+	if err = p.writeManifest(checksum); err != nil {
+		return err
+	}
+
+	if err = p.postInstall(ctx); err != nil {
+		return err
+	}
+
+	// A template without a go.mod has nothing to tidy.
+	if p.Tidy && utils.Exists(filepath.Join(p.Dest, filepath.FromSlash(p.ModuleDir), "go.mod")) {
+		if err = runCommand(ctx, filepath.Join(p.Dest, filepath.FromSlash(p.ModuleDir)), "go", "mod", "tidy"); err != nil {
+			return err
+		}
+	}
+
+	if p.Build {
+		if err = Build(ctx, filepath.Join(p.Dest, filepath.FromSlash(p.ModuleDir))); err != nil {
+			return err
+		}
+	}
+
+	if p.Gitignore {
+		created.track(filepath.Join(p.Dest, ".gitignore"))
+		if err = utils.WriteGoGitignore(p.Dest); err != nil {
+			return err
+		}
+	}
+
+	if p.GitInit {
+		created.track(filepath.Join(p.Dest, ".git"))
+		if err = gitInit(ctx, p.Dest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setReplaces sets the `Replaces` directives to the project's go.mod and updates its `Installed` digest.
+func (p *Project) setReplaces() error {
+	name := path.Join(p.ModuleDir, "go.mod")
+	modFile := filepath.Join(p.Dest, filepath.FromSlash(name))
+
+	contents, err := ioutil.ReadFile(modFile)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("project <%s> has no go.mod to set the replace directives", p.Dest)
+		}
+		return err
+	}
+
+	oldPaths := make([]string, 0, len(p.Replaces))
+	for oldPath := range p.Replaces {
+		oldPaths = append(oldPaths, oldPath)
+	}
+	sort.Strings(oldPaths)
+
+	for _, oldPath := range oldPaths {
+		if contents, err = utils.SetGoModReplace(contents, oldPath, p.Replaces[oldPath]); err != nil {
+			return err
+		}
+		p.logf("replace <%s> with <%s>", oldPath, p.Replaces[oldPath])
+	}
+
+	if err = ioutil.WriteFile(modFile, contents, defaultFilePerm); err != nil {
 		return err
 	}
 
-	return p.unzip(b)
+	if _, ok := p.Installed[name]; ok {
+		sum := sha256.Sum256(contents)
+		p.Installed[name] = hex.EncodeToString(sum[:])
+	}
+
+	return nil
 }
 
-func (p *Project) download() ([]byte, error) {
-	p.Version = strings.Split(p.Version, " ")[0]
-	if p.Version == "latest" {
-		p.Version = "master"
+// FileInfo describes a file of a project's archive, as it's recorded by the archive's directory.
+type FileInfo struct {
+	// Path is the slash-separated path of the file, relative to the archive's root folder (or `Subdir`).
+	Path string
+	// Size is the uncompressed size of the file in bytes.
+	Size int64
+	// CRC32 is the checksum of the uncompressed contents, as recorded by the zip header.
+	CRC32 uint32
+	// Mode is the file's mode and permission bits, e.g. a symbolic link or an executable.
+	Mode os.FileMode
+}
+
+// Files returns the archive's files, sorted by path, without extracting or decompressing them.
+// The archive is downloaded or read from the cache as `Install` does.
+func (p *Project) Files() ([]FileInfo, error) {
+	return p.FilesContext(context.Background())
+}
+
+// FilesContext same as `Files` but it accepts a context which can cancel the download.
+func (p *Project) FilesContext(ctx context.Context) ([]FileInfo, error) {
+	c := *p
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	r, _, release, err := c.open(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
-	zipURL := fmt.Sprintf("https://github.com/%s/archive/%s.zip", p.Repo, p.Version) // e.g. https://github.com/kataras/iris-cli/archive/master.zip
-	r, err := utils.DownloadReader(zipURL, nil)
+	compressedRootFolder, files, err := c.archiveFiles(r)
 	if err != nil {
 		return nil, err
 	}
-	defer r.Close()
 
-	if p.Reader != nil {
-		return p.Reader(r)
+	infos := make([]FileInfo, 0, len(files))
+	for _, f := range files {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		infos = append(infos, FileInfo{
+			Path:  strings.TrimPrefix(f.Name, compressedRootFolder),
+			Size:  int64(f.UncompressedSize64),
+			CRC32: f.CRC32,
+			Mode:  f.Mode(),
+		})
 	}
 
-	return ioutil.ReadAll(r)
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Path < infos[j].Path
+	})
+
+	return infos, nil
 }
 
-func (p *Project) unzip(body []byte) error {
-	r, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+// open downloads the project's archive and opens it as zip, the "release" closes and removes it.
+// It returns the checksum of the downloaded archive too, e.g. for the manifest.
+func (p *Project) open(ctx context.Context) (r *zip.Reader, checksum string, release func(), err error) {
+	zipFile, releaseDownload, err := p.download(ctx)
 	if err != nil {
-		return err
+		return nil, "", nil, err
 	}
 
-	if len(r.File) == 0 {
-		return fmt.Errorf("empty zip")
+	if checksum, err = fileChecksum(zipFile); err != nil {
+		releaseDownload()
+		return nil, "", nil, err
 	}
 
-	first := r.File[0]
-	if !first.FileInfo().IsDir() {
-		return fmt.Errorf("expected a root folder but got <%s>", first.Name)
+	zipFile, releaseZip, err := zipArchive(zipFile, p.archiveFormat(), p.maxSize())
+	if err != nil {
+		releaseDownload()
+		return nil, "", nil, err
 	}
 
-	if base := filepath.Base(p.Repo); !strings.Contains(first.Name, base) {
-		return fmt.Errorf("expected root folder to match the repository name <%s> but got <%s>", base, first.Name)
+	if p.Password != "" {
+		decrypted, err := decryptZip(zipFile, p.Password, p.maxSize())
+		if err != nil {
+			releaseZip()
+			releaseDownload()
+			return nil, "", nil, err
+		}
+
+		releaseEncrypted := releaseZip
+		zipFile, releaseZip = decrypted, func() {
+			os.Remove(decrypted)
+			releaseEncrypted()
+		}
 	}
-	compressedRootFolder := first.Name // e.g. iris-master
 
-	var oldModuleName []byte
-	// Find current module name, starting from the end because list is sorted alphabetically
-	// and "go.mod" is more likely to be visible at the end.
-	modFile := filepath.Join(compressedRootFolder, "go.mod")
-	for i := len(r.File) - 1; i > 0; i-- {
-		f := r.File[i]
-		if filepath.Clean(f.Name) == modFile {
-			rc, err := f.Open()
-			if err != nil {
-				return err
+	rc, err := zip.OpenReader(zipFile)
+	if err != nil {
+		releaseZip()
+		releaseDownload()
+		return nil, "", nil, err
+	}
+
+	release = func() {
+		rc.Close()
+		releaseZip()
+		releaseDownload()
+	}
+
+	if isEncrypted(rc.File) {
+		release()
+		return nil, "", nil, fmt.Errorf("project <%s> version <%s> is password-protected, please set the password", p.Name, p.Version)
+	}
+
+	return &rc.Reader, checksum, release, nil
+}
+
+// archiveFormat returns the format of the project's archive, declared by its provider,
+// or `FormatDetect` for a local archive or directory. The module zips of `GoProxy` are zip ones.
+func (p *Project) archiveFormat() ArchiveFormat {
+	if p.Archive != "" || p.Dir != "" {
+		return FormatDetect
+	}
+
+	if p.GoProxy {
+		return FormatZip
+	}
+
+	provider, _ := ProviderOf(p.Repo)
+	return provider.Format
+}
+
+// InstalledFiles returns the sorted paths of the files written by the last `Install`.
+func (p *Project) InstalledFiles() []string {
+	files := make([]string, 0, len(p.Installed))
+	for name := range p.Installed {
+		files = append(files, filepath.Join(p.Dest, filepath.FromSlash(name)))
+	}
+
+	sort.Strings(files)
+	return files
+}
+
+// Validate reports whether the project's fields are valid, it's called by `Install`
+// before any network work. An empty `Version` is set to "master", the `Repo` is normalized,
+// see `normalizeRepo`, and the `Subdir` is cleaned.
+func (p *Project) Validate() error {
+	if p.Version == "" {
+		p.Version = "master"
+	}
+
+	if p.Archive == "" && p.Dir == "" {
+		p.Repo = normalizeRepo(p.Repo)
+		if p.GoProxy {
+			if err := utils.CheckModulePath(p.Repo); err != nil {
+				return fmt.Errorf("invalid module: %w", err)
 			}
 
-			contents, err := ioutil.ReadAll(rc)
-			if err != nil {
+			if _, err := goProxies(p.Repo); err != nil {
 				return err
 			}
+		} else if err := validateRepo(p.Repo); err != nil {
+			return err
+		}
+	}
+
+	if p.ModuleDir != "" {
+		moduleDir := path.Clean(filepath.ToSlash(p.ModuleDir))
+		if path.IsAbs(moduleDir) || moduleDir == ".." || strings.HasPrefix(moduleDir, "../") {
+			return fmt.Errorf("invalid module directory <%s>: expected a path inside the project", p.ModuleDir)
+		}
+
+		if moduleDir == "." {
+			moduleDir = ""
+		}
+		p.ModuleDir = moduleDir
+	}
+
+	if p.StripComponents < 0 {
+		return fmt.Errorf("invalid strip components <%d>: expected a positive number", p.StripComponents)
+	}
+
+	if p.Subdir != "" {
+		subdir := path.Clean(filepath.ToSlash(p.Subdir))
+		if path.IsAbs(subdir) || subdir == ".." || strings.HasPrefix(subdir, "../") {
+			return fmt.Errorf("invalid subdirectory <%s>: expected a path inside the repository", p.Subdir)
+		}
+
+		if subdir == "." {
+			subdir = ""
+		}
+		p.Subdir = subdir
+	}
 
-			oldModuleName = utils.ModulePath(contents)
-			if p.Module == "" {
-				// if new module name is empty, then default it to the remote one.
-				p.Module = string(oldModuleName)
+	for _, patterns := range [][]string{p.Include, p.Exclude, p.AppNameFiles} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern <%s>: %w", pattern, err)
 			}
+		}
+	}
 
-			break
+	if p.Module != "" {
+		if err := utils.CheckModulePath(p.Module); err != nil {
+			return fmt.Errorf("invalid module: %w", err)
 		}
 	}
 
-	if len(oldModuleName) == 0 {
-		// no go mod found, stop here  as we dont' support non-go modules, Iris depends on go 1.13.
-		return fmt.Errorf("project <%s> version <%s> is not a go module, please try other version", p.Name, p.Version)
+	for _, placeholder := range p.Placeholders {
+		if err := utils.CheckModulePath(placeholder); err != nil {
+			return fmt.Errorf("invalid placeholder: %w", err)
+		}
 	}
 
-	var (
-		newModuleName = []byte(p.Module)
-		shouldReplace = !bytes.Equal(oldModuleName, newModuleName)
-	)
+	if p.OldModule != "" {
+		if err := utils.CheckModulePath(p.OldModule); err != nil {
+			return fmt.Errorf("invalid old module: %w", err)
+		}
+	}
+
+	for oldPath, newPath := range p.Replaces {
+		if fields := strings.Fields(oldPath); len(fields) == 0 || len(fields) > 2 {
+			return fmt.Errorf("invalid replace <%s>: expected a module path and an optional version", oldPath)
+		} else if err := utils.CheckModulePath(fields[0]); err != nil {
+			return fmt.Errorf("invalid replace: %w", err)
+		}
 
-	p.Dest = utils.Dest(p.Dest)
+		if fields := strings.Fields(newPath); len(fields) == 0 || len(fields) > 2 {
+			return fmt.Errorf("invalid replace <%s> => <%s>: expected a directory or a module path and a version", oldPath, newPath)
+		}
+	}
 
-	for _, f := range r.File {
-		// without the /$project-$version root folder, so it can be used to dest as it is without creating a new folder based on the project name.
-		name := strings.TrimPrefix(f.Name, compressedRootFolder)
-		if name == "" {
-			// root folder.
-			continue
+	return nil
+}
+
+// normalizeRepo returns the "repo" without a scheme, a ".git" suffix and surrounding slashes,
+// e.g. "https://github.com/kataras/iris.git/" as it's pasted from a browser or a git remote
+// becomes "github.com/kataras/iris".
+func normalizeRepo(repo string) string {
+	repo = strings.TrimSpace(repo)
+	if i := strings.Index(repo, "://"); i >= 0 {
+		repo = repo[i+len("://"):]
+	}
+
+	repo = strings.Trim(repo, "/")
+	repo = strings.TrimSuffix(repo, ".git")
+	return strings.Trim(repo, "/")
+}
+
+// validateRepo reports whether "repo" is an "owner/name" or "host/owner/name" repository.
+func validateRepo(repo string) error {
+	parts := strings.Split(repo, "/")
+	if len(parts) < 2 {
+		return fmt.Errorf("malformed repository <%s>: expected owner/name or host/owner/name", repo)
+	}
+
+	for _, part := range parts {
+		if part == "" {
+			return fmt.Errorf("malformed repository <%s>: empty path element", repo)
 		}
-		fpath := filepath.Join(p.Dest, name)
+	}
+
+	if len(parts) > 2 && !strings.Contains(parts[0], ".") {
+		return fmt.Errorf("malformed repository <%s>: expected a host like github.com but got <%s>", repo, parts[0])
+	}
+
+	return nil
+}
+
+// runCommand runs the "name" command with "args" inside the "dir" directory,
+// its output is part of the returned error on failure.
+func runCommand(ctx context.Context, dir, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s %s: %w\n%s", name, strings.Join(args, " "), err, out)
+	}
+
+	return nil
+}
+
+// gitInit initializes a git repository inside "dir" and commits all of its files.
+// It does nothing if git is not installed.
+func gitInit(ctx context.Context, dir string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil
+	}
 
-		// https://snyk.io/research/zip-slip-vulnerability#go
-		if !strings.HasPrefix(fpath, p.Dest+string(os.PathSeparator)) {
-			return fmt.Errorf("illegal path: %s", fpath)
+	for _, args := range [][]string{
+		{"init"},
+		{"add", "-A"},
+		{"commit", "-m", "Initial commit"},
+	} {
+		if err := runCommand(ctx, dir, "git", args...); err != nil {
+			return err
 		}
+	}
 
-		if f.FileInfo().IsDir() {
-			os.MkdirAll(fpath, os.ModePerm)
-			continue
+	return nil
+}
+
+// archiveFiles returns the root folder of the zip "r", including the `Subdir`, e.g. iris-master/
+// and the files under it, with their `StripComponents` removed.
+func (p *Project) archiveFiles(r *zip.Reader) (string, []*zip.File, error) {
+	if len(r.File) == 0 {
+		return "", nil, fmt.Errorf("empty zip")
+	}
+
+	root := rootFolder
+	if p.GoProxy {
+		root = moduleRootFolder
+	}
+
+	compressedRootFolder, err := root(r.File) // e.g. iris-master/
+	if err != nil {
+		return "", nil, err
+	}
+
+	files := r.File
+	if p.StripComponents > 0 {
+		if files = stripComponents(files, compressedRootFolder, p.StripComponents); len(files) == 0 {
+			return "", nil, fmt.Errorf("project <%s> version <%s> has no files after stripping %d path elements", p.Name, p.Version, p.StripComponents)
+		}
+	}
+
+	if p.Subdir != "" {
+		// Use the subdirectory's files only, as it was the root folder.
+		compressedRootFolder += p.Subdir + "/"
+		if files = filesOf(files, compressedRootFolder); len(files) == 0 {
+			return "", nil, fmt.Errorf("project <%s> version <%s> does not contain the <%s> subdirectory", p.Name, p.Version, p.Subdir)
+		}
+	}
+
+	return compressedRootFolder, files, nil
+}
+
+// unzip extracts the files of the "r" archive, the created files and directories are removed on failure,
+// unless `KeepOnError` is true.
+func (p *Project) unzip(ctx context.Context, r *zip.Reader) error {
+	return p.unzipTracked(ctx, r, &rollback{fs: p.fs()})
+}
+
+// unzipTracked same as `unzip` but it records the created files and directories to "created",
+// so they can be removed on a later failure too, e.g. a canceled installation.
+func (p *Project) unzipTracked(ctx context.Context, r *zip.Reader, created *rollback) (err error) {
+	compressedRootFolder, files, err := p.archiveFiles(r)
+	if err != nil {
+		return err
+	}
+
+	var (
+		oldModuleName []byte
+		modFile       *zip.File
+	)
+	// Find current module name, the zip entries are not sorted in any specific order,
+	// so match the exact go.mod path (zip paths are always slash-separated).
+	modFilename := path.Join(compressedRootFolder, p.ModuleDir, "go.mod")
+	for _, f := range files {
+		if path.Clean(f.Name) == modFilename {
+			modFile = f
+			break
 		}
+	}
 
-		outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+	if modFile == nil && p.ModuleDir == "" {
+		// The module may be inside a subdirectory, e.g. "backend" next to a "frontend".
+		modFile = nestedModFile(files, compressedRootFolder)
+	}
+
+	if modFile != nil {
+		rc, err := modFile.Open()
 		if err != nil {
 			return err
 		}
 
-		rc, err := f.Open()
+		contents, err := ioutil.ReadAll(rc)
+		rc.Close()
 		if err != nil {
 			return err
 		}
 
-		// If new(local) module name differs the current(remote) one.
-		if shouldReplace {
-			contents, err := ioutil.ReadAll(rc)
-			if err != nil {
-				return err
-			}
+		oldModuleName = utils.ModulePath(contents)
+		p.MinGo = utils.GoVersion(contents)
+		if p.ModuleDir = path.Dir(strings.TrimPrefix(modFile.Name, compressedRootFolder)); p.ModuleDir == "." {
+			p.ModuleDir = ""
+		}
+		p.logf("detected module <%s> from <%s>", oldModuleName, modFile.Name)
+		if p.Module == "" {
+			// if new module name is empty, then default it to the remote one.
+			p.Module = string(oldModuleName)
+		}
+	}
 
-			newContents := bytes.ReplaceAll(contents, oldModuleName, newModuleName)
-			_, err = outFile.Write(newContents)
-		} else {
-			_, err = io.Copy(outFile, rc)
+	if len(oldModuleName) == 0 {
+		if p.Module == "" {
+			// no go mod found and no module to name the destination after, stop here
+			// instead of resolving an empty destination, Iris depends on go 1.13.
+			return fmt.Errorf("project <%s> version <%s> is %w, please try other version or set the module", p.Name, p.Version, ErrNotModule)
 		}
 
-		outFile.Close()
-		rc.Close()
+		// A template without a go.mod, e.g. of static files, nothing to rewrite.
+		p.logf("no go.mod found, module <%s> names the destination only", p.Module)
+	}
 
-		if err != nil {
-			return err
+	if err = checkGoVersion(p.MinGo); err != nil {
+		return fmt.Errorf("project <%s> version <%s>: %w", p.Name, p.Version, err)
+	}
+
+	if p.OldModule != "" {
+		oldModuleName = []byte(p.OldModule)
+	}
+
+	c, files, err := readConfig(files, compressedRootFolder)
+	if err != nil {
+		return err
+	}
+	p.PostInstall = c.PostInstall
+
+	appNameToken := p.appNameToken(c.AppName, string(oldModuleName))
+	if len(appNameToken) > 0 {
+		p.logf("app name <%s> is replaced with <%s>", appNameToken, p.AppName)
+	}
+
+	newModuleName := []byte(p.Module)
+	var oldModules [][]byte // the import paths which are rewritten to the new module name.
+	switch {
+	case len(oldModuleName) == 0:
+		// no go.mod, see above.
+	case bytes.Equal(oldModuleName, newModuleName):
+		p.logf("module <%s> is kept as it is", oldModuleName)
+	default:
+		p.logf("module <%s> is replaced with <%s>", oldModuleName, newModuleName)
+		oldModules = append(oldModules, oldModuleName)
+	}
+
+	for _, placeholder := range p.Placeholders {
+		if placeholder != p.Module && placeholder != string(oldModuleName) {
+			p.logf("placeholder <%s> is replaced with <%s>", placeholder, newModuleName)
+			oldModules = append(oldModules, []byte(placeholder))
 		}
 	}
 
-	// Don't use Module name for path because it may contains a version suffix.
-	// newPath := filepath.Join(dest, p.Name)
-	// os.RemoveAll(newPath)
-	// err = os.Rename(filepath.Join(dest, compressedRootFolder), newPath)
-	// if err == nil {
-	// 	p.InstalledPath = newPath
-	// }
+	p.Dest = resolveDest(p.Dest, p.Module, p.GOPATH)
+	p.logf("destination resolved to <%s>", p.Dest)
 
-	return nil
+	if len(p.Include) > 0 || len(p.Exclude) > 0 || p.ExcludeCI {
+		filtered := files[:0:0]
+		for _, f := range files {
+			if name := strings.TrimPrefix(f.Name, compressedRootFolder); name == "" || p.shouldExtract(name) {
+				filtered = append(filtered, f)
+			}
+		}
+		files = filtered
+	}
+
+	if err = p.checkCaseCollisions(files, compressedRootFolder); err != nil {
+		return err
+	}
+
+	if err = p.checkSize(files); err != nil {
+		return err
+	}
+
+	if !p.DryRun {
+		switch p.Overwrite {
+		case "", OverwriteFail:
+			if existing := p.existingFiles(files, compressedRootFolder); len(existing) > 0 {
+				return fmt.Errorf("%w: %d file(s) in <%s>, e.g. <%s>, please use the skip or force overwrite policy", ErrFilesExist, len(existing), p.Dest, existing[0])
+			}
+		case OverwriteSkip, OverwriteForce:
+		default:
+			return fmt.Errorf("unknown overwrite policy <%s>", p.Overwrite)
+		}
+	}
+
+	defer func() {
+		if err != nil && !p.KeepOnError {
+			created.undo()
+		}
+	}()
+
+	var (
+		jobs      []extractJob
+		extracted int // the number of the extracted entries, see `ExtractProgress`.
+	)
+
+	// Resolve the paths and create the directories in order, the files are extracted concurrently later on.
+	for _, f := range files {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		job, ok, jobErr := p.prepare(f, compressedRootFolder, created)
+		if jobErr != nil {
+			return jobErr
+		}
+
+		if ok {
+			jobs = append(jobs, job)
+			continue
+		}
+
+		extracted++
+		if p.ExtractProgress != nil {
+			p.ExtractProgress(extracted, len(files))
+		}
+	}
+
+	if p.DryRun {
+		if p.Rewrites != nil && len(oldModules) > 0 {
+			return p.reportRewrites(ctx, files, compressedRootFolder, oldModules)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		installed  = make(map[string]string)
+		extractErr error
+		mu         sync.Mutex // protects the above, "extracted" and the `ExtractProgress` calls.
+		wg         sync.WaitGroup
+		jobsCh     = make(chan extractJob)
+	)
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for job := range jobsCh {
+				checksum, jobErr := p.extract(ctx, job, oldModules, newModuleName, appNameToken)
+
+				mu.Lock()
+				if jobErr != nil {
+					if extractErr == nil {
+						extractErr = jobErr
+						cancel() // stop the rest of the workers.
+					}
+				} else {
+					installed[filepath.ToSlash(job.name)] = checksum
+
+					extracted++
+					if p.ExtractProgress != nil {
+						p.ExtractProgress(extracted, len(files))
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+send:
+	for _, job := range jobs {
+		select {
+		case <-ctx.Done():
+			break send
+		case jobsCh <- job:
+		}
+	}
+	close(jobsCh)
+	wg.Wait()
+
+	if extractErr != nil {
+		return extractErr
+	}
+
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+
+	// The files are written to their final path, without the root folder, so there is no
+	// final rename of the extracted directory which could fail across different devices.
+	p.Installed = installed
+	return nil
+}
+
+// DefaultMaxSize is the limit of the total uncompressed size of a project when `Project.MaxSize` is zero.
+const DefaultMaxSize int64 = 1 << 30 // 1 GiB.
+
+// checkSize returns an error if the declared uncompressed size of the "files" exceeds the `MaxSize`.
+func (p *Project) checkSize(files []*zip.File) error {
+	maxSize := p.maxSize()
+	if maxSize < 0 {
+		return nil
+	}
+
+	var total uint64
+	for _, f := range files {
+		total += f.UncompressedSize64
+		if total > uint64(maxSize) {
+			return fmt.Errorf("project <%s> version <%s> is %w: more than %d bytes, <%s> exceeds the limit", p.Name, p.Version, ErrTooLarge, maxSize, f.Name)
+		}
+	}
+
+	return nil
+}
+
+// maxSize returns the `MaxSize` or the `DefaultMaxSize` if it's zero, a negative value disables the limit.
+func (p *Project) maxSize() int64 {
+	if p.MaxSize == 0 {
+		return DefaultMaxSize
+	}
+
+	return p.MaxSize
+}
+
+// copyLimited copies "src" to "dst" as long as the copied bytes do not exceed the "remaining" ones,
+// which are decreased by the copied bytes, e.g. to convert an archive without filling the disk on a zip bomb.
+// It fails with an `ErrTooLarge` once the limit is exceeded. A negative "remaining" disables the limit.
+func copyLimited(dst io.Writer, src io.Reader, remaining *int64) error {
+	if *remaining < 0 {
+		_, err := io.Copy(dst, src)
+		return err
+	}
+
+	n, err := io.CopyN(dst, src, *remaining+1)
+	if err == io.EOF {
+		*remaining -= n
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return ErrTooLarge
+}
+
+// The permissions of the extracted files and directories when the archive does not contain them.
+const (
+	defaultFilePerm os.FileMode = 0644
+	defaultDirPerm  os.FileMode = 0755
+)
+
+// caseInsensitiveFS reports whether the file system is case-insensitive, as the default ones of macOS and Windows.
+var caseInsensitiveFS = runtime.GOOS == "darwin" || runtime.GOOS == "windows"
+
+// checkCaseCollisions reports the files which differ only by case, e.g. "Config.go" and "config.go",
+// as the last one would silently overwrite the other on a case-insensitive file system.
+// It fails with an `ErrCaseCollision` on such a file system, otherwise the files are extracted and it only logs them.
+func (p *Project) checkCaseCollisions(files []*zip.File, compressedRootFolder string) error {
+	seen := make(map[string]string, len(files))
+	for _, f := range files {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		name := strings.TrimPrefix(f.Name, compressedRootFolder)
+		if p.isTemplate(name) {
+			name = strings.TrimSuffix(name, templateExt)
+		}
+
+		key := strings.ToLower(name)
+		other, ok := seen[key]
+		if !ok {
+			seen[key] = name
+			continue
+		}
+
+		if caseInsensitiveFS {
+			return fmt.Errorf("project <%s> version <%s>: %w: <%s> and <%s> differ only by case", p.Name, p.Version, ErrCaseCollision, other, name)
+		}
+
+		p.logf("warning: <%s> and <%s> differ only by case, they collide on case-insensitive file systems", other, name)
+	}
+
+	return nil
+}
+
+// extractBufferSize is the maximum size of the write buffer of each extracted file.
+const extractBufferSize = 32 * 1024
+
+// copyBuffers are the reusable buffers which the files are copied through, instead of one per file.
+var copyBuffers = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, extractBufferSize)
+		return &buf
+	},
+}
+
+// dirPerm returns the permissions of the "f" directory entry, defaults to `defaultDirPerm`.
+func dirPerm(f *zip.File) os.FileMode {
+	if perm := f.Mode().Perm(); perm != 0 {
+		return perm
+	}
+
+	return defaultDirPerm
+}
+
+// extractJob is a file of the archive to be extracted.
+type extractJob struct {
+	f          *zip.File
+	name       string // the path relative to the destination, without the template extension.
+	fpath      string
+	isTemplate bool
+}
+
+// prepare validates the archive entry "f" and creates its directory, if it's a directory,
+// or its parent directory, if it's a file. It reports whether the entry is a file which should be extracted.
+// On `DryRun` it reports the file to the `Preview` instead.
+func (p *Project) prepare(f *zip.File, compressedRootFolder string, created *rollback) (extractJob, bool, error) {
+	// without the /$project-$version root folder, so it can be used to dest as it is without creating a new folder based on the project name.
+	name := strings.TrimPrefix(f.Name, compressedRootFolder)
+	if name == "" {
+		// root folder.
+		return extractJob{}, false, nil
+	}
+
+	isTemplate := p.isTemplate(name)
+	if isTemplate {
+		name = strings.TrimSuffix(name, templateExt)
+	}
+	fpath := filepath.Join(p.Dest, name)
+
+	// https://snyk.io/research/zip-slip-vulnerability#go
+	if !strings.HasPrefix(fpath, p.Dest+string(os.PathSeparator)) {
+		return extractJob{}, false, fmt.Errorf("%w: %s", ErrIllegalPath, fpath)
+	}
+
+	if p.DryRun {
+		if p.Preview != nil && !f.FileInfo().IsDir() {
+			p.Preview(fpath, p.exists(fpath))
+		}
+		return extractJob{}, false, nil
+	}
+
+	if f.FileInfo().IsDir() {
+		p.logf("create directory <%s>", fpath)
+		return extractJob{}, false, created.mkdirAll(fpath, dirPerm(f))
+	}
+
+	if p.Overwrite == OverwriteSkip && p.exists(fpath) {
+		p.logf("skip existing file <%s>", fpath)
+		return extractJob{}, false, nil
+	}
+
+	// Not all archives contain entries for directories.
+	if err := created.mkdirAll(filepath.Dir(fpath), defaultDirPerm); err != nil {
+		return extractJob{}, false, err
+	}
+
+	created.track(fpath)
+	p.logf("extract <%s> to <%s>", f.Name, fpath)
+	return extractJob{f: f, name: name, fpath: fpath, isTemplate: isTemplate}, true, nil
+}
+
+// reportRewrites reports the files which contain any of the "oldModules" to the `Rewrites`, see `DryRun`.
+func (p *Project) reportRewrites(ctx context.Context, files []*zip.File, compressedRootFolder string, oldModules [][]byte) error {
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if f.FileInfo().IsDir() || f.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		contents, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+
+		occurrences := 0
+		for _, oldModule := range oldModules {
+			occurrences += bytes.Count(contents, oldModule)
+		}
+		if occurrences == 0 {
+			continue
+		}
+
+		name := strings.TrimPrefix(f.Name, compressedRootFolder)
+		rewritten := !p.isTemplate(name) && isModuleFile(name) && !utils.IsBinary(contents)
+		p.Rewrites(filepath.Join(p.Dest, filepath.FromSlash(name)), occurrences, rewritten)
+	}
+
+	return nil
+}
+
+// extract writes the "job" file and returns the SHA256 hex digest of its written contents,
+// the "oldModules" import paths of the go files, if any, are replaced with the "newModule"
+// and the "appNameToken" of the `AppNameFiles`, if any, is replaced with the `AppName`.
+// Symbolic links are created but they have no checksum. The copy stops once the "ctx" is done.
+func (p *Project) extract(ctx context.Context, job extractJob, oldModules [][]byte, newModule, appNameToken []byte) (string, error) {
+	f := job.f
+	if f.Mode()&os.ModeSymlink != 0 {
+		return "", p.symlink(f, job.fpath)
+	}
+
+	perm := f.Mode().Perm()
+	if perm == 0 {
+		perm = defaultFilePerm
+	}
+
+	outFile, err := p.fs().OpenFile(job.fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return "", err
+	}
+	defer outFile.Close()
+
+	// Keep the executable bits of scripts under a restrictive umask, or of an existing file.
+	if err = p.fs().Chmod(job.fpath, perm); err != nil {
+		return "", err
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	src := utils.ContextReader(ctx, rc)
+
+	h := sha256.New()
+	// Buffer the writes, up to the file's size, so a small file is written at once.
+	bufSize := extractBufferSize
+	if size := f.UncompressedSize64; size < uint64(bufSize) {
+		bufSize = int(size) // the default size if zero.
+	}
+	bw := bufio.NewWriterSize(outFile, bufSize)
+	w := io.MultiWriter(bw, h)
+
+	if job.isTemplate {
+		var contents []byte
+		if contents, err = ioutil.ReadAll(src); err == nil {
+			if contents, err = executeTemplate(job.name, contents, p.Vars); err == nil {
+				_, err = w.Write(p.format(job.name, contents))
+			}
+		}
+	} else if len(oldModules) > 0 && isModuleFile(job.name) { // If new(local) module name differs the current(remote) one or there are placeholders.
+		var contents []byte
+		if contents, err = ioutil.ReadAll(src); err == nil {
+			replaced := replaceModule(job.name, contents, oldModules, newModule)
+			if !bytes.Equal(replaced, contents) {
+				replaced = p.format(job.name, replaced)
+			}
+			_, err = w.Write(replaced)
+		}
+	} else if len(appNameToken) > 0 && p.isAppNameFile(job.name) {
+		var contents []byte
+		if contents, err = ioutil.ReadAll(src); err == nil {
+			_, err = w.Write(replaceAppName(contents, appNameToken, []byte(p.AppName)))
+		}
+	} else {
+		buf := copyBuffers.Get().(*[]byte)
+		_, err = io.CopyBuffer(w, src, *buf)
+		copyBuffers.Put(buf)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if err = bw.Flush(); err != nil {
+		return "", err
+	}
+
+	if err = outFile.Close(); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// logf calls the `Logf`, if not nil.
+func (p *Project) logf(format string, args ...interface{}) {
+	if p.Logf != nil {
+		p.Logf(format, args...)
+	}
+}
+
+// resolveDest returns the absolute destination directory of a project with "module".
+// If "dest" is empty then it's the ./$name directory, where $name is
+// the last element of the module path without its major version suffix,
+// e.g. "app" for both "github.com/org/group/app" and "github.com/org/group/app/v2",
+// or, if "gopath" is true, the $GOPATH/src/$module directory (the first GOPATH entry or its default, $HOME/go).
+func resolveDest(dest, module string, gopath bool) string {
+	if dest == "" && module != "" {
+		if gopath {
+			dest = filepath.Join(gopathDir(), "src", filepath.FromSlash(module))
+		} else {
+			dest = utils.ModuleName(module)
+		}
+	}
+
+	return utils.Dest(dest)
+}
+
+// gopathDir returns the first entry of the GOPATH environment variable or, if it's not set, its default one.
+func gopathDir() string {
+	if list := filepath.SplitList(os.Getenv("GOPATH")); len(list) > 0 && list[0] != "" {
+		return list[0]
+	}
+
+	return build.Default.GOPATH
+}
+
+// nestedModFile returns the go.mod file of the "files" which is closest to the "compressedRootFolder",
+// the first one in the archive's order wins between files of the same depth.
+// The go.mod files of vendor and testdata directories are ignored.
+func nestedModFile(files []*zip.File, compressedRootFolder string) (modFile *zip.File) {
+	depth := -1
+	for _, f := range files {
+		name := strings.TrimPrefix(f.Name, compressedRootFolder)
+		if path.Base(name) != "go.mod" || f.FileInfo().IsDir() {
+			continue
+		}
+
+		if dir := "/" + path.Dir(name) + "/"; strings.Contains(dir, "/vendor/") || strings.Contains(dir, "/testdata/") {
+			continue
+		}
+
+		if n := strings.Count(name, "/"); depth == -1 || n < depth {
+			modFile, depth = f, n
+		}
+	}
+
+	return
+}
+
+// stripComponents returns copies of the "files" with the first "n" path elements after the "compressedRootFolder"
+// removed from their names, the files (and directories) with fewer path elements are skipped.
+// The copies read the same archive's contents.
+func stripComponents(files []*zip.File, compressedRootFolder string, n int) (stripped []*zip.File) {
+	for _, f := range files {
+		elems := strings.SplitN(strings.TrimPrefix(f.Name, compressedRootFolder), "/", n+1)
+		if len(elems) <= n || elems[n] == "" {
+			continue
+		}
+
+		c := *f
+		c.Name = compressedRootFolder + elems[n]
+		stripped = append(stripped, &c)
+	}
+
+	return
+}
+
+// filesOf returns the "files" which are inside the "folder", e.g. "iris-master/_examples/".
+func filesOf(files []*zip.File, folder string) (filtered []*zip.File) {
+	for _, f := range files {
+		if strings.HasPrefix(f.Name, folder) {
+			filtered = append(filtered, f)
+		}
+	}
+
+	return
+}
+
+// DefaultCIExcludes are the patterns of the CI and VCS metadata which are not extracted on `Project.ExcludeCI`.
+var DefaultCIExcludes = []string{
+	".git",
+	".github",
+	".gitlab",
+	".gitlab-ci.yml",
+	".circleci",
+	".travis.yml",
+	".drone.yml",
+	".buildkite",
+	"appveyor.yml",
+	".appveyor.yml",
+	"azure-pipelines.yml",
+	"bitbucket-pipelines.yml",
+	"Jenkinsfile",
+}
+
+// shouldExtract reports whether the file "name", relative to the project's root,
+// passes the `Include` and `Exclude` patterns and the `ExcludeCI` ones.
+func (p *Project) shouldExtract(name string) bool {
+	name = strings.TrimSuffix(name, "/")
+	if matchAny(p.Exclude, name) || p.ExcludeCI && matchAny(DefaultCIExcludes, name) {
+		return false
+	}
+
+	return len(p.Include) == 0 || matchAny(p.Include, name)
+}
+
+// matchAny reports whether any of the "patterns" matches the "name", its base name
+// (if the pattern has no slash) or any of its parent directories.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(pattern, "/")
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(name)); ok {
+				return true
+			}
+		}
+
+		for p := name; p != "."; p = path.Dir(p) {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+const templateExt = ".tmpl"
+
+// isTemplate reports whether the file "name" should be executed with the `Vars`.
+func (p *Project) isTemplate(name string) bool {
+	return len(p.Vars) > 0 && strings.HasSuffix(name, templateExt) && name != templateExt
+}
+
+// executeTemplate executes the "contents" template file "name" with the "vars" and returns the result.
+func executeTemplate(name string, contents []byte, vars map[string]string) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(contents))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, vars); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// format returns the gofmt-ed "contents" of a go source file "name" if `Format` is true.
+// The "contents" are returned as they are if they can't be parsed.
+func (p *Project) format(name string, contents []byte) []byte {
+	if !p.Format || path.Ext(name) != ".go" {
+		return contents
+	}
+
+	if formatted, err := format.Source(contents); err == nil {
+		return formatted
+	}
+
+	return contents
+}
+
+// existingFiles returns the local files of the destination which the archive "files" would overwrite.
+func (p *Project) existingFiles(files []*zip.File, compressedRootFolder string) (existing []string) {
+	for _, f := range files {
+		name := strings.TrimPrefix(f.Name, compressedRootFolder)
+		if name == "" || f.FileInfo().IsDir() {
+			continue
+		}
+
+		if p.isTemplate(name) {
+			name = strings.TrimSuffix(name, templateExt)
+		}
+
+		if fpath := filepath.Join(p.Dest, name); p.exists(fpath) {
+			existing = append(existing, fpath)
+		}
+	}
+
+	return
+}
+
+// isModuleFile reports whether the file "name" may contain the module name,
+// only go source files and go.mod can.
+func isModuleFile(name string) bool {
+	base := path.Base(name)
+	return path.Ext(base) == ".go" || base == "go.mod"
+}
+
+// replaceModule rewrites the "oldModules" to "newModule" of a module file's "contents",
+// the module declaration of go.mod and the import paths of go source files.
+// The go.mod is parsed, so its other directives are kept, e.g. a replace directive of the old module.
+func replaceModule(name string, contents []byte, oldModules [][]byte, newModule []byte) []byte {
+	if path.Base(name) == "go.mod" {
+		return utils.ReplaceModulePath(contents, string(newModule))
+	}
+
+	if utils.IsBinary(contents) {
+		return contents
+	}
+
+	for _, oldModule := range oldModules {
+		contents = utils.ReplaceImportPaths(contents, string(oldModule), string(newModule))
+	}
+
+	return contents
+}
+
+// symlink creates the symbolic link of the "f" archive entry to "fpath".
+// The link's target must be inside the destination directory too.
+func (p *Project) symlink(f *zip.File, fpath string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	target, err := ioutil.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return err
+	}
+
+	resolved := filepath.FromSlash(string(target))
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(filepath.Dir(fpath), resolved)
+	}
+
+	// https://snyk.io/research/zip-slip-vulnerability#go
+	if resolved = filepath.Clean(resolved); resolved != p.Dest && !strings.HasPrefix(resolved, p.Dest+string(os.PathSeparator)) {
+		return fmt.Errorf("illegal link: %s -> %s: %w", fpath, target, ErrIllegalPath)
+	}
+
+	// Symlink fails if the file already exists.
+	if err = p.fs().Remove(fpath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return p.fs().Symlink(string(target), fpath)
+}
+
+// rollback keeps track of the files and directories created by an extraction,
+// so they can be removed when it fails.
+type rollback struct {
+	fs    FS
+	paths []string
+	// dirs are the directories which are known to exist, created or not,
+	// so the files of the same directory do not check their parents again.
+	dirs map[string]struct{}
+}
+
+// track records the "path" if it does not exist yet.
+func (r *rollback) track(path string) {
+	if !fsExists(r.fs, path) {
+		r.paths = append(r.paths, path)
+	}
+}
+
+// mkdirAll same as `os.MkdirAll` but it records the top-most created directory.
+// The "dir" has exactly the "perm" permissions if it's created, regardless of the umask,
+// and its missing parents the `defaultDirPerm` ones.
+func (r *rollback) mkdirAll(dir string, perm os.FileMode) error {
+	if _, ok := r.dirs[dir]; ok {
+		return nil
+	}
+
+	missing := ""
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, ok := r.dirs[d]; ok || fsExists(r.fs, d) {
+			break
+		}
+
+		missing = d
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	if missing != "" {
+		if err := r.fs.MkdirAll(dir, defaultDirPerm); err != nil {
+			return err
+		}
+
+		r.paths = append(r.paths, missing)
+
+		for d, dperm := dir, perm; ; d, dperm = filepath.Dir(d), defaultDirPerm {
+			if err := r.fs.Chmod(d, dperm); err != nil {
+				return err
+			}
+
+			if d == missing {
+				break
+			}
+		}
+	}
+
+	if r.dirs == nil {
+		r.dirs = make(map[string]struct{})
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, ok := r.dirs[d]; ok {
+			break
+		}
+		r.dirs[d] = struct{}{}
+
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	return nil
+}
+
+// undo removes the recorded paths, in reverse order.
+func (r *rollback) undo() {
+	for i := len(r.paths) - 1; i >= 0; i-- {
+		r.fs.RemoveAll(r.paths[i])
+	}
+}
+
+// rootFolder returns the top-level folder which all "files" share, e.g. "iris-master/".
+// It's resolved from the archive's contents because its name depends on the
+// repository host, the fork, the branch and the tag format.
+func rootFolder(files []*zip.File) (string, error) {
+	var root string
+	for _, f := range files {
+		i := strings.IndexByte(f.Name, '/')
+		if i == -1 {
+			return "", fmt.Errorf("expected a root folder but got <%s>", f.Name)
+		}
+
+		if name := f.Name[:i+1]; root == "" {
+			root = name
+		} else if name != root {
+			return "", fmt.Errorf("expected a single root folder but got <%s> and <%s>", root, name)
+		}
+	}
+
+	return root, nil
 }
@@ -0,0 +1,1765 @@
+package project
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go/build"
+	"hash/crc32"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+type testFile struct {
+	Name     string
+	Contents string
+}
+
+func newTestZip(t testing.TB, files ...testFile) *zip.Reader {
+	t.Helper()
+
+	body := newTestArchive(t, files...)
+	r, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+func newTestArchive(t testing.TB, files ...testFile) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for _, f := range files {
+		fw, err := w.Create(f.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = fw.Write([]byte(f.Contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// newTestProvider registers a provider which serves the "body" archive
+// and returns a repository which can be installed through it.
+func newTestProvider(t testing.TB, body []byte) (string, func()) {
+	t.Helper()
+
+	return newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Write(body)
+	})
+}
+
+func newTestProviderHandler(t testing.TB, handler http.HandlerFunc) (string, func()) {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+
+	provider := &Provider{
+		Host: srv.Listener.Addr().String(),
+		ArchiveURL: func(repo, version string) string {
+			return fmt.Sprintf("%s/%s/%s.zip", srv.URL, repo, url.PathEscape(version))
+		},
+	}
+
+	providers = append(providers, provider)
+	return provider.Host + "/author/project", func() {
+		providers = providers[0 : len(providers)-1]
+		srv.Close()
+	}
+}
+
+func newTestDest(t testing.TB) string {
+	t.Helper()
+
+	dest, err := ioutil.TempDir("", "iris-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dest
+}
+
+func expectFile(t *testing.T, path, expected string) {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(b); expected != got {
+		t.Fatalf("[%s] expected contents:\n%s\nbut got:\n%s", path, expected, got)
+	}
+}
+
+func TestProjectUnzipRootFolder(t *testing.T) {
+	r := newTestZip(t,
+		testFile{"fork-1.2.3/", ""},
+		testFile{"fork-1.2.3/go.mod", "module github.com/author/project\n"},
+		testFile{"fork-1.2.3/main.go", "package main\n\nimport _ \"github.com/author/project/sub\"\n"},
+		testFile{"fork-1.2.3/sub/sub.go", "package sub\n"},
+	)
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Version: "v1.2.3", Dest: dest, Module: "github.com/author/newproject"}
+	if err := p.unzip(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(dest, "go.mod"), "module github.com/author/newproject\n")
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n\nimport _ \"github.com/author/newproject/sub\"\n")
+	expectFile(t, filepath.Join(dest, "sub", "sub.go"), "package sub\n")
+
+	expectedFiles := []string{filepath.Join(dest, "go.mod"), filepath.Join(dest, "main.go"), filepath.Join(dest, "sub", "sub.go")}
+	if got := p.InstalledFiles(); !reflect.DeepEqual(expectedFiles, got) {
+		t.Fatalf("expected installed files:\n%v\nbut got:\n%v", expectedFiles, got)
+	}
+}
+
+func TestProjectUnzipSubdir(t *testing.T) {
+	r := newTestZip(t,
+		testFile{"starters-master/README.md", "# Starters\n"},
+		testFile{"starters-master/go.mod", "module github.com/org/starters\n"},
+		testFile{"starters-master/rest-api/main.go", "package main\n\nimport _ \"github.com/org/starters/rest-api/sub\"\n"},
+		testFile{"starters-master/rest-api/sub/sub.go", "package sub\n"},
+		testFile{"starters-master/rest-api/go.mod", "module github.com/org/starters/rest-api\n"},
+		testFile{"starters-master/rest-api-v2/go.mod", "module github.com/org/starters/rest-api-v2\n"},
+	)
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "starters", Repo: "org/starters", Subdir: "./rest-api/", Dest: dest, Module: "app"}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.unzip(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(dest, "go.mod"), "module app\n")
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n\nimport _ \"app/sub\"\n")
+	expectFile(t, filepath.Join(dest, "sub", "sub.go"), "package sub\n")
+
+	for _, name := range []string{"README.md", "rest-api", "rest-api-v2"} {
+		if utils.Exists(filepath.Join(dest, name)) {
+			t.Fatalf("expected %s to not be extracted", name)
+		}
+	}
+
+	p.Subdir = "missing"
+	if err := p.unzip(context.Background(), r); err == nil || !strings.Contains(err.Error(), "subdirectory") {
+		t.Fatalf("expected a missing subdirectory error but got: %v", err)
+	}
+}
+
+func TestProjectUnzipStripComponents(t *testing.T) {
+	newZip := func() *zip.Reader {
+		return newTestZip(t,
+			testFile{"project-master/README.md", "# wrapper\n"},
+			testFile{"project-master/dist/", ""},
+			testFile{"project-master/dist/go.mod", "module github.com/author/project\n"},
+			testFile{"project-master/dist/main.go", "package main\n"},
+			testFile{"project-master/dist/api/go.mod", "module github.com/author/project/api\n"},
+			testFile{"project-master/dist/api/main.go", "package main\n"},
+		)
+	}
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, StripComponents: 1, Module: "app"}
+	if err := p.unzip(context.Background(), newZip()); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(dest, "go.mod"), "module app\n")
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n")
+	expectFile(t, filepath.Join(dest, "api", "main.go"), "package main\n")
+	for _, name := range []string{"README.md", "dist"} {
+		if utils.Exists(filepath.Join(dest, name)) {
+			t.Fatalf("expected %s to not be extracted", name)
+		}
+	}
+
+	// The subdirectory is relative to the stripped paths.
+	dest = newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p = &Project{Name: "project", Repo: "author/project", Dest: dest, StripComponents: 1, Subdir: "api"}
+	if err := p.unzip(context.Background(), newZip()); err != nil {
+		t.Fatal(err)
+	}
+	expectFile(t, filepath.Join(dest, "go.mod"), "module github.com/author/project/api\n")
+
+	p = &Project{Name: "project", Repo: "author/project", Dest: dest, StripComponents: 3}
+	if err := p.unzip(context.Background(), newZip()); err == nil {
+		t.Fatalf("expected an error when there are no files after stripping")
+	}
+
+	if err := (&Project{Repo: "author/project", StripComponents: -1}).Validate(); err == nil {
+		t.Fatalf("expected an invalid strip components error")
+	}
+}
+
+func TestProjectUnzipIncludeExclude(t *testing.T) {
+	r := newTestZip(t,
+		testFile{"project-master/.github/workflows/ci.yml", "name: CI\n"},
+		testFile{"project-master/docs/guide/intro.md", "# Intro\n"},
+		testFile{"project-master/README.md", "# Project\n"},
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/main_test.go", "package main\n"},
+		testFile{"project-master/views/index.html", "<h1>Index</h1>\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	)
+
+	tests := []struct {
+		include  []string
+		exclude  []string
+		expected []string
+	}{
+		{nil, []string{".github", "docs/*", "*.md"}, []string{"go.mod", "main.go", "main_test.go", "views/index.html"}},
+		{[]string{"*.go", "go.mod"}, []string{"*_test.go"}, []string{"go.mod", "main.go"}},
+		{[]string{"views"}, nil, []string{"views/index.html"}},
+		{[]string{"docs/guide/*.md"}, []string{"docs"}, nil},
+	}
+
+	for i, tt := range tests {
+		dest := newTestDest(t)
+		defer os.RemoveAll(dest)
+
+		p := &Project{Name: "project", Repo: "author/project", Dest: dest, Include: tt.include, Exclude: tt.exclude}
+		if err := p.unzip(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		filepath.Walk(dest, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				rel, _ := filepath.Rel(dest, path)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			return err
+		})
+
+		if !reflect.DeepEqual(tt.expected, got) {
+			t.Fatalf("[%d] expected files:\n%v\nbut got:\n%v", i, tt.expected, got)
+		}
+	}
+}
+
+func TestProjectUnzipExcludeCI(t *testing.T) {
+	r := newTestZip(t,
+		testFile{"project-master/.github/workflows/ci.yml", "on: push\n"},
+		testFile{"project-master/.gitignore", "bin/\n"},
+		testFile{"project-master/.travis.yml", "language: go\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+		testFile{"project-master/main.go", "package main\n"},
+	)
+
+	tests := []struct {
+		excludeCI bool
+		expected  []string
+	}{
+		{true, []string{".gitignore", "go.mod", "main.go"}},
+		{false, []string{".github/workflows/ci.yml", ".gitignore", ".travis.yml", "go.mod", "main.go"}},
+	}
+
+	for i, tt := range tests {
+		dest := newTestDest(t)
+		defer os.RemoveAll(dest)
+
+		p := &Project{Name: "project", Repo: "author/project", Dest: dest, ExcludeCI: tt.excludeCI}
+		if err := p.unzip(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		filepath.Walk(dest, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				rel, _ := filepath.Rel(dest, path)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			return err
+		})
+
+		if !reflect.DeepEqual(tt.expected, got) {
+			t.Fatalf("[%d] expected files:\n%v\nbut got:\n%v", i, tt.expected, got)
+		}
+	}
+}
+
+func TestProjectUnzipMultipleRootFolders(t *testing.T) {
+	r := newTestZip(t,
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+		testFile{"other-master/main.go", "package main\n"},
+	)
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest}
+	if err := p.unzip(context.Background(), r); err == nil {
+		t.Fatalf("expected an error for multiple root folders")
+	}
+}
+
+func TestProjectUnzipCanceled(t *testing.T) {
+	r := newTestZip(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	)
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest}
+	if err := p.unzip(ctx, r); err != context.Canceled {
+		t.Fatalf("expected error: %v but got %v", context.Canceled, err)
+	}
+
+	if utils.Exists(filepath.Join(dest, "main.go")) {
+		t.Fatalf("expected main.go to not be extracted after cancelation")
+	}
+}
+
+func TestProjectInstallCanceled(t *testing.T) {
+	files := []testFile{
+		{"project-master/go.mod", "module github.com/author/project\n"},
+		{"project-master/" + ConfigFilename, "postInstall:\n  - echo generated > generated.txt\n"},
+	}
+	for i := 0; i < 50; i++ {
+		files = append(files, testFile{fmt.Sprintf("project-master/pkg%d/file.go", i), fmt.Sprintf("package pkg%d\n", i)})
+	}
+
+	repo, closeProvider := newTestProvider(t, newTestArchive(t, files...))
+	defer closeProvider()
+
+	root := newTestDest(t)
+	defer os.RemoveAll(root)
+
+	// The downloaded archive is a temporary file too, see `NoCache`.
+	tmp := filepath.Join(root, "tmp")
+	if err := os.Mkdir(tmp, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"TMPDIR", "TMP", "TEMP"} {
+		defer os.Setenv(key, os.Getenv(key))
+		os.Setenv(key, tmp)
+	}
+
+	tests := []struct {
+		name   string
+		cancel func(p *Project, cancel context.CancelFunc)
+	}{
+		{"extraction", func(p *Project, cancel context.CancelFunc) {
+			p.ExtractProgress = func(current, total int) {
+				if current == 1 {
+					cancel()
+				}
+			}
+		}},
+		{"post install", func(p *Project, cancel context.CancelFunc) {
+			p.Confirm = func([]string) bool {
+				cancel()
+				return true
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := New("project", repo)
+		p.Dest = filepath.Join(root, "app")
+		p.NoCache = true
+		tt.cancel(p, cancel)
+		if err := p.InstallContext(ctx); err == nil {
+			t.Fatalf("[%s] expected an error for a canceled installation", tt.name)
+		}
+
+		if utils.Exists(p.Dest) {
+			t.Fatalf("[%s] expected the destination <%s> to be removed", tt.name, p.Dest)
+		}
+
+		if leftovers, _ := ioutil.ReadDir(tmp); len(leftovers) > 0 {
+			t.Fatalf("[%s] expected no leftover temporary files but got <%s>", tt.name, leftovers[0].Name())
+		}
+	}
+}
+
+func TestProjectInstall(t *testing.T) {
+	repo, closeProvider := newTestProvider(t, newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n\nimport _ \"github.com/author/project/sub\"\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	var downloaded, extracted int64
+	p := New("project", repo)
+	p.Dest = dest
+	p.Module = "newproject"
+	p.Progress = func(current, total int64) {
+		if current == total {
+			downloaded = current
+		}
+	}
+	p.ExtractProgress = func(current, total int) {
+		extracted = int64(current)
+	}
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	if downloaded == 0 {
+		t.Fatalf("expected download progress to be completed")
+	}
+
+	if expected, got := int64(2), extracted; expected != got {
+		t.Fatalf("expected %d extracted entries but got %d", expected, got)
+	}
+
+	expectFile(t, filepath.Join(dest, "go.mod"), "module newproject\n")
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n\nimport _ \"newproject/sub\"\n")
+}
+
+func BenchmarkProjectInstall(b *testing.B) {
+	// A 50MB archive, it's streamed to a temporary file instead of kept in memory.
+	contents := bytes.Repeat([]byte("0123456789"), 5*1024*1024)
+	repo, closeProvider := newTestProvider(b, newTestArchive(b,
+		testFile{"project-master/assets/data.bin", string(contents)},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	defer closeProvider()
+
+	dest := newTestDest(b)
+	defer os.RemoveAll(dest)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p := New("project", repo)
+		p.Dest = dest
+		p.Overwrite = OverwriteForce
+		p.NoCache = true
+		if err := p.Install(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProjectUnzip(b *testing.B) {
+	files := []testFile{{"project-master/go.mod", "module github.com/author/project\n"}}
+	for i := 0; i < 500; i++ {
+		files = append(files, testFile{
+			fmt.Sprintf("project-master/pkg%d/file%d.go", i%20, i),
+			fmt.Sprintf("package pkg%d\n\nimport _ \"github.com/author/project/pkg0\"\n\n// %s\n", i%20, strings.Repeat("x", 1024)),
+		})
+	}
+	// Keep the go.mod last, it's searched from the end.
+	files = append(files[1:], files[0])
+	r := newTestZip(b, files...)
+
+	dest := newTestDest(b)
+	defer os.RemoveAll(dest)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p := &Project{Name: "project", Repo: "author/project", Dest: dest, Module: "newproject", Overwrite: OverwriteForce}
+		if err := p.unzip(context.Background(), r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProjectUnzipSmallFiles(b *testing.B) {
+	files := []testFile{{"project-master/go.mod", "module github.com/author/project\n"}}
+	for i := 0; i < 500; i++ {
+		files = append(files, testFile{fmt.Sprintf("project-master/assets/dir%d/file%d.txt", i%10, i), "tiny\n"})
+	}
+	r := newTestZip(b, files...)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		dest := newTestDest(b)
+		b.StartTimer()
+
+		p := &Project{Name: "project", Repo: "author/project", Dest: dest}
+		if err := p.unzip(context.Background(), r); err != nil {
+			b.Fatal(err)
+		}
+
+		b.StopTimer()
+		os.RemoveAll(dest)
+		b.StartTimer()
+	}
+}
+
+func TestProjectInstallLocal(t *testing.T) {
+	src := newTestDest(t)
+	defer os.RemoveAll(src)
+
+	for name, contents := range map[string]string{
+		"go.mod":      "module github.com/author/project\n",
+		"main.go":     "package main\n\nimport _ \"github.com/author/project/sub\"\n",
+		".git/config": "[core]\n",
+	} {
+		fpath := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fpath, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	archive, err := ioutil.TempFile("", "project-*.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(archive.Name())
+
+	archive.Write(newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n\nimport _ \"github.com/author/project/sub\"\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	archive.Close()
+
+	for _, p := range []*Project{NewFromArchive("", archive.Name()), NewFromDir("", src)} {
+		dest := newTestDest(t)
+		defer os.RemoveAll(dest)
+
+		p.Dest = dest
+		p.Module = "newproject"
+		if err := p.Install(); err != nil {
+			t.Fatal(err)
+		}
+
+		expectFile(t, filepath.Join(dest, "go.mod"), "module newproject\n")
+		expectFile(t, filepath.Join(dest, "main.go"), "package main\n\nimport _ \"newproject/sub\"\n")
+		if utils.Exists(filepath.Join(dest, ".git")) {
+			t.Fatalf("expected the .git directory to not be copied")
+		}
+	}
+
+	p := NewFromArchive(newTestDest(t), archive.Name())
+	defer os.RemoveAll(p.Dest)
+	p.Checksum = "invalid"
+	if err := p.Install(); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected a checksum mismatch error but got: %v", err)
+	}
+}
+
+func TestProjectInstallChecksum(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	)
+	repo, closeProvider := newTestProvider(t, body)
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := New("project", repo)
+	p.Dest = dest
+	p.Checksum = "0000"
+	if err := p.Install(); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected a checksum mismatch error but got: %v", err)
+	}
+
+	sum := sha256.Sum256(body)
+	p.Checksum = hex.EncodeToString(sum[:])
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProjectFiles(t *testing.T) {
+	repo, closeProvider := newTestProvider(t, newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+		testFile{"project-master/web/public/index.html", "<html></html>\n"},
+		testFile{"project-master/web/app.go", "package web\n"},
+	))
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	os.RemoveAll(dest)
+
+	p := New("project", repo)
+	p.Dest = dest
+	files, err := p.Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := []string{"go.mod", "main.go", "web/app.go", "web/public/index.html"}, filePaths(files); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected files: %v but got: %v", expected, got)
+	}
+
+	contents := "<html></html>\n"
+	if f := files[3]; f.Size != int64(len(contents)) || f.CRC32 != crc32.ChecksumIEEE([]byte(contents)) || !f.Mode.IsRegular() {
+		t.Fatalf("expected the size, the checksum and the mode of the zip header but got: %#+v", f)
+	}
+
+	if utils.Exists(dest) {
+		t.Fatalf("expected nothing to be written to the destination")
+	}
+
+	p.Subdir = "web"
+	if files, err = p.Files(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := []string{"app.go", "public/index.html"}, filePaths(files); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected files: %v but got: %v", expected, got)
+	}
+}
+
+func filePaths(files []FileInfo) []string {
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+
+	return paths
+}
+
+func TestProjectUnzipSymlink(t *testing.T) {
+	newSymlinkZip := func(target string) *zip.Reader {
+		buf := new(bytes.Buffer)
+		w := zip.NewWriter(buf)
+
+		fw, _ := w.Create("project-master/main.go")
+		fw.Write([]byte("package main\n"))
+		fw, _ = w.Create("project-master/configs/app.yml")
+		fw.Write([]byte("Port: 8080\n"))
+
+		h := &zip.FileHeader{Name: "project-master/app.yml"}
+		h.SetMode(os.ModeSymlink | 0777)
+		fw, _ = w.CreateHeader(h)
+		fw.Write([]byte(target))
+
+		fw, _ = w.Create("project-master/go.mod")
+		fw.Write([]byte("module github.com/author/project\n"))
+
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, Overwrite: OverwriteForce}
+	if err := p.unzip(context.Background(), newSymlinkZip("configs/app.yml")); err != nil {
+		t.Fatal(err)
+	}
+
+	link := filepath.Join(dest, "app.yml")
+	if info, err := os.Lstat(link); err != nil {
+		t.Fatal(err)
+	} else if info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected <%s> to be a symbolic link", link)
+	}
+	expectFile(t, link, "Port: 8080\n")
+
+	if err := p.unzip(context.Background(), newSymlinkZip("../../etc/passwd")); err == nil || !strings.Contains(err.Error(), "illegal link") {
+		t.Fatalf("expected an illegal link error but got: %v", err)
+	}
+}
+
+func TestProjectUnzipPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permissions are not supported")
+	}
+
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for _, f := range []struct {
+		name     string
+		mode     os.FileMode
+		contents string
+	}{
+		{"project-master/", os.ModeDir | 0755, ""},
+		{"project-master/scripts/", os.ModeDir | 0750, ""},
+		{"project-master/scripts/build.sh", 0755, "#!/bin/sh\ngo build\n"},
+		{"project-master/main.go", 0644, "package main\n"},
+		{"project-master/go.mod", 0644, "module github.com/author/project\n"},
+	} {
+		h := &zip.FileHeader{Name: f.name, Method: zip.Deflate}
+		h.SetMode(f.mode)
+		fw, err := w.CreateHeader(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = fw.Write([]byte(f.contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := newTestDest(t)
+	defer os.RemoveAll(root)
+
+	dest := filepath.Join(root, "project")
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest}
+	if err = p.unzip(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, expected := range map[string]os.FileMode{
+		dest:                           0755,
+		filepath.Join(dest, "scripts"): 0750,
+		filepath.Join(dest, "scripts", "build.sh"): 0755,
+		filepath.Join(dest, "main.go"):             0644,
+	} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := info.Mode().Perm(); expected != got {
+			t.Fatalf("[%s] expected permissions: %s but got: %s", path, expected, got)
+		}
+	}
+}
+
+func TestProjectUnzipRollback(t *testing.T) {
+	newZip := func() *zip.Reader {
+		return newTestZip(t,
+			testFile{"project-master/main.go", "package main\n"},
+			testFile{"project-master/sub/sub.go", "package sub\n"},
+			testFile{"project-master/go.mod", "module github.com/author/project\n"},
+			testFile{"project-master/../../evil.go", "package evil\n"},
+		)
+	}
+
+	root := newTestDest(t)
+	defer os.RemoveAll(root)
+
+	existing := filepath.Join(root, "existing.txt")
+	if err := ioutil.WriteFile(existing, []byte("keep me"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: root}
+	if err := p.unzip(context.Background(), newZip()); err == nil {
+		t.Fatalf("expected an illegal path error")
+	}
+
+	files, err := ioutil.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := 1, len(files); expected != got {
+		t.Fatalf("expected %d file after rollback but got %d", expected, got)
+	}
+	expectFile(t, existing, "keep me")
+
+	// Destination does not exist before the installation.
+	dest := filepath.Join(root, "new", "project")
+	p = &Project{Name: "project", Repo: "author/project", Dest: dest}
+	if err := p.unzip(context.Background(), newZip()); err == nil {
+		t.Fatalf("expected an illegal path error")
+	}
+
+	if utils.Exists(filepath.Join(root, "new")) {
+		t.Fatalf("expected destination to be removed after rollback")
+	}
+
+	// The paths are validated before extracting any file, so use an extraction error instead.
+	p = &Project{Name: "project", Repo: "author/project", Dest: dest, KeepOnError: true, Vars: map[string]string{"Name": "app"}}
+	err = p.unzip(context.Background(), newTestZip(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/sub/README.md.tmpl", "# {{.Missing}}\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	if err == nil {
+		t.Fatalf("expected a template error")
+	}
+
+	if !utils.Exists(filepath.Join(dest, "sub")) {
+		t.Fatalf("expected the partially extracted files to be kept")
+	}
+}
+
+func TestProjectUnzipCurrentDir(t *testing.T) {
+	dir := newTestDest(t)
+	defer os.RemoveAll(dir)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err = ioutil.WriteFile("notes.txt", []byte("keep me"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	newZip := func(readme string) *zip.Reader {
+		return newTestZip(t,
+			testFile{"project-master/main.go", "package main\n\nimport _ \"github.com/author/project/sub\"\n"},
+			testFile{"project-master/sub/README.md.tmpl", readme},
+			testFile{"project-master/go.mod", "module github.com/author/project\n"},
+		)
+	}
+
+	// A failed installation removes only its own files.
+	p := &Project{Name: "project", Repo: "author/project", Dest: ".", Module: "github.com/me/app", Vars: map[string]string{"Name": "app"}}
+	if err = p.unzip(context.Background(), newZip("# {{.Missing}}\n")); err == nil {
+		t.Fatalf("expected a template error")
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, got := 1, len(files); expected != got {
+		t.Fatalf("expected %d file after rollback but got %d", expected, got)
+	}
+	expectFile(t, filepath.Join(dir, "notes.txt"), "keep me")
+
+	p = &Project{Name: "project", Repo: "author/project", Dest: ".", Module: "github.com/me/app", Vars: map[string]string{"Name": "app"}}
+	if err = p.unzip(context.Background(), newZip("# app\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if realDir, _ := filepath.EvalSymlinks(dir); p.Dest != dir && p.Dest != realDir {
+		t.Fatalf("expected destination: %s but got: %s", dir, p.Dest)
+	}
+	expectFile(t, filepath.Join(dir, "main.go"), "package main\n\nimport _ \"github.com/me/app/sub\"\n")
+	expectFile(t, filepath.Join(dir, "sub", "README.md"), "# app\n")
+	expectFile(t, filepath.Join(dir, "notes.txt"), "keep me")
+}
+
+func TestProjectUnzipModuleFiles(t *testing.T) {
+	const oldModule = "github.com/author/project"
+	png := "\x89PNG\r\n\x1a\n\x00\x00" + oldModule
+	r := newTestZip(t,
+		testFile{"project-master/README.md", "go get " + oldModule + "\n"},
+		testFile{"project-master/assets/logo.png", png},
+		testFile{"project-master/main.go", "package main\n\nimport _ \"" + oldModule + "/sub\"\n"},
+		testFile{"project-master/go.mod", "module " + oldModule + "\n"},
+	)
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, Module: "newproject"}
+	if err := p.unzip(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(dest, "README.md"), "go get "+oldModule+"\n")
+	expectFile(t, filepath.Join(dest, "assets", "logo.png"), png)
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n\nimport _ \"newproject/sub\"\n")
+	expectFile(t, filepath.Join(dest, "go.mod"), "module newproject\n")
+}
+
+func TestProjectUnzipGoModReplace(t *testing.T) {
+	// The replace directives which refer to the old module, e.g. of a tools submodule, are not module declarations.
+	goMod := `module github.com/author/project
+
+require github.com/author/project/tools v0.0.0
+
+replace github.com/author/project/tools => ./tools
+
+replace (
+	github.com/author/project => ./
+	yourapp/tools => ./tools
+)
+`
+	r := newTestZip(t,
+		testFile{"project-master/go.mod", goMod},
+		testFile{"project-master/main.go", "package main\n\nimport _ \"github.com/author/project/tools\"\n"},
+	)
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, Module: "newproject", Placeholders: []string{"yourapp"}}
+	if err := p.unzip(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(dest, "go.mod"), strings.Replace(goMod, "module github.com/author/project", "module newproject", 1))
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n\nimport _ \"newproject/tools\"\n")
+}
+
+func TestProjectUnzipNestedModule(t *testing.T) {
+	newZip := func() *zip.Reader {
+		return newTestZip(t,
+			testFile{"project-master/README.md", "# project\n"},
+			testFile{"project-master/backend/main.go", "package main\n\nimport _ \"github.com/author/project/backend/sub\"\n"},
+			testFile{"project-master/backend/vendor/github.com/dep/go.mod", "module github.com/dep\n"},
+			testFile{"project-master/backend/go.mod", "module github.com/author/project/backend\n"},
+			testFile{"project-master/frontend/tools/go.mod", "module github.com/author/project/tools\n"},
+		)
+	}
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, Module: "github.com/me/app"}
+	if err := p.unzip(context.Background(), newZip()); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "backend", p.ModuleDir; expected != got {
+		t.Fatalf("expected module directory: %s but got: %s", expected, got)
+	}
+	expectFile(t, filepath.Join(dest, "README.md"), "# project\n")
+	expectFile(t, filepath.Join(dest, "backend", "go.mod"), "module github.com/me/app\n")
+	expectFile(t, filepath.Join(dest, "backend", "main.go"), "package main\n\nimport _ \"github.com/me/app/sub\"\n")
+
+	// The go.mod can be selected explicitly.
+	dest = newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p = &Project{Name: "project", Repo: "author/project", Dest: dest, ModuleDir: "frontend/tools"}
+	if err := p.unzip(context.Background(), newZip()); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "github.com/author/project/tools", p.Module; expected != got {
+		t.Fatalf("expected module: %s but got: %s", expected, got)
+	}
+}
+
+func TestProjectUnzipNoModule(t *testing.T) {
+	newZip := func() *zip.Reader {
+		return newTestZip(t,
+			testFile{"site-master/index.html", "<h1>Site</h1>\n"},
+			testFile{"site-master/assets/app.js", "console.log(\"site\");\n"},
+		)
+	}
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	existing := filepath.Join(dest, "keep.txt")
+	if err := ioutil.WriteFile(existing, []byte("keep\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a module there is no name for the destination.
+	p := &Project{Name: "site", Repo: "author/site", Dest: dest}
+	if err := p.unzip(context.Background(), newZip()); !errors.Is(err, ErrNotModule) {
+		t.Fatalf("expected a not module error but got: %v", err)
+	}
+
+	expectFile(t, existing, "keep\n")
+	if utils.Exists(filepath.Join(dest, "index.html")) {
+		t.Fatalf("expected index.html to not be extracted")
+	}
+
+	p = &Project{Name: "site", Repo: "author/site", Dest: dest, Module: "github.com/me/site"}
+	if err := p.unzip(context.Background(), newZip()); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, existing, "keep\n")
+	expectFile(t, filepath.Join(dest, "index.html"), "<h1>Site</h1>\n")
+	expectFile(t, filepath.Join(dest, "assets", "app.js"), "console.log(\"site\");\n")
+	if utils.Exists(filepath.Join(dest, "go.mod")) {
+		t.Fatalf("expected no go.mod to be written")
+	}
+}
+
+func TestProjectUnzipModuleFirstEntry(t *testing.T) {
+	for _, moduleDir := range []string{"", "tools"} {
+		modFilename := path.Join("project-master", moduleDir, "go.mod")
+		r := newTestZip(t,
+			testFile{modFilename, "module github.com/author/project\n"},
+			testFile{"project-master/main.go", "package main\n"},
+			testFile{"project-master/tools/main.go", "package main\n"},
+		)
+
+		dest := newTestDest(t)
+		defer os.RemoveAll(dest)
+
+		p := &Project{Name: "project", Repo: "author/project", Dest: dest, ModuleDir: moduleDir, Module: "github.com/me/app"}
+		if err := p.unzip(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+
+		expectFile(t, filepath.Join(dest, filepath.FromSlash(moduleDir), "go.mod"), "module github.com/me/app\n")
+	}
+}
+
+func TestProjectUnzipOldModule(t *testing.T) {
+	r := newTestZip(t,
+		testFile{"project-master/main.go", "package main\n\nimport (\n\t_ \"github.com/author/project/sub\"\n\t_ \"github.com/vendored/lib/x\"\n)\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	)
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, OldModule: "github.com/vendored/lib"}
+	if err := p.unzip(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n\nimport (\n\t_ \"github.com/author/project/sub\"\n\t_ \"github.com/author/project/x\"\n)\n")
+	expectFile(t, filepath.Join(dest, "go.mod"), "module github.com/author/project\n")
+}
+
+func TestProjectUnzipPlaceholders(t *testing.T) {
+	r := newTestZip(t,
+		testFile{"project-master/README.md", "import \"yourapp/routes\"\n"},
+		testFile{"project-master/main.go", "package main\n\nimport (\n\t_ \"example.com/changeme/models\"\n\t_ \"github.com/author/project/sub\"\n\t_ \"yourapp/routes\"\n\t_ \"yourapplication\"\n)\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	)
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, Module: "github.com/me/app",
+		Placeholders: []string{"yourapp", "example.com/changeme"}}
+	if err := p.unzip(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(dest, "README.md"), "import \"yourapp/routes\"\n")
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n\nimport (\n\t_ \"github.com/me/app/models\"\n\t_ \"github.com/me/app/sub\"\n\t_ \"github.com/me/app/routes\"\n\t_ \"yourapplication\"\n)\n")
+	expectFile(t, filepath.Join(dest, "go.mod"), "module github.com/me/app\n")
+}
+
+func TestProjectUnzipAppName(t *testing.T) {
+	newZip := func(config string) *zip.Reader {
+		files := []testFile{
+			{"project-master/Dockerfile", "RUN go build -o /bin/project .\nENTRYPOINT [\"/bin/project\"]\n"},
+			{"project-master/deploy/project.service", "ExecStart=/usr/local/bin/project-server\nDescription=projects\n"},
+			{"project-master/README.md", "# project\n"},
+			{"project-master/main.go", "package main\n\nimport _ \"github.com/author/project/sub\"\n\nconst name = \"project\"\n"},
+			{"project-master/go.mod", "module github.com/author/project/v2\n"},
+		}
+		if config != "" {
+			files = append(files, testFile{"project-master/" + ConfigFilename, config})
+		}
+		return newTestZip(t, files...)
+	}
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, Module: "github.com/me/app", AppName: "myapp"}
+	if err := p.unzip(context.Background(), newZip("")); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(dest, "Dockerfile"), "RUN go build -o /bin/myapp .\nENTRYPOINT [\"/bin/myapp\"]\n")
+	expectFile(t, filepath.Join(dest, "deploy", "project.service"), "ExecStart=/usr/local/bin/myapp-server\nDescription=projects\n")
+	// Not matched by the default patterns.
+	expectFile(t, filepath.Join(dest, "README.md"), "# project\n")
+	// The go files are handled by the module rename only.
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n\nimport _ \"github.com/author/project/sub\"\n\nconst name = \"project\"\n")
+
+	// The token declared by the project's config and custom patterns.
+	dest = newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p = &Project{Name: "project", Repo: "author/project", Dest: dest, AppName: "myapp", AppNameFiles: []string{"*.md"}}
+	if err := p.unzip(context.Background(), newZip("appName: bin\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(dest, "Dockerfile"), "RUN go build -o /bin/project .\nENTRYPOINT [\"/bin/project\"]\n")
+	expectFile(t, filepath.Join(dest, "README.md"), "# project\n")
+
+	dest = newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p = &Project{Name: "project", Repo: "author/project", Dest: dest, AppName: "myapp", AppNameToken: "project", AppNameFiles: []string{"*.md"}}
+	if err := p.unzip(context.Background(), newZip("appName: bin\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(dest, "README.md"), "# myapp\n")
+
+	if err := (&Project{Repo: "author/project", AppNameFiles: []string{"["}}).Validate(); err == nil {
+		t.Fatalf("expected an invalid pattern error")
+	}
+}
+
+func TestProjectUnzipTemplates(t *testing.T) {
+	const oldModule = "github.com/author/project"
+	newZip := func(readme string) *zip.Reader {
+		return newTestZip(t,
+			testFile{"project-master/README.md.tmpl", readme},
+			testFile{"project-master/main.go.tmpl", "package main\n\nimport _ \"" + oldModule + "/sub\"\n\nconst app = \"{{.AppName}}\"\n"},
+			testFile{"project-master/views/index.html", "<h1>{{.Title}}</h1>\n"},
+			testFile{"project-master/go.mod", "module " + oldModule + "\n"},
+		)
+	}
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, Module: "newproject",
+		Vars: map[string]string{"AppName": "myapp", "Author": "kataras"}}
+	if err := p.unzip(context.Background(), newZip("# {{.AppName}} by {{.Author}}\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(dest, "README.md"), "# myapp by kataras\n")
+	// Templates are not part of the module replacement.
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n\nimport _ \""+oldModule+"/sub\"\n\nconst app = \"myapp\"\n")
+	expectFile(t, filepath.Join(dest, "views", "index.html"), "<h1>{{.Title}}</h1>\n")
+	if utils.Exists(filepath.Join(dest, "README.md.tmpl")) {
+		t.Fatalf("expected the template file to be saved without its suffix")
+	}
+
+	p.Overwrite = OverwriteForce
+	err := p.unzip(context.Background(), newZip("# {{.Missing}}\n"))
+	if err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Fatalf("expected a missing key error but got: %v", err)
+	}
+}
+
+func TestProjectUnzipFormat(t *testing.T) {
+	r := newTestZip(t,
+		testFile{"project-master/main.go", "package main\n\nimport (\n\t\"fmt\"\n  _ \"github.com/author/project/sub\"\n)\n\nfunc main()  { fmt.Println() }\n"},
+		testFile{"project-master/sub/sub.go", "package sub\n\nvar  X = 1\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	)
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, Module: "newproject", Format: true}
+	if err := p.unzip(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n\nimport (\n\t\"fmt\"\n\t_ \"newproject/sub\"\n)\n\nfunc main() { fmt.Println() }\n")
+	// Not rewritten files are kept as they are.
+	expectFile(t, filepath.Join(dest, "sub", "sub.go"), "package sub\n\nvar  X = 1\n")
+}
+
+func TestProjectUnzipDryRun(t *testing.T) {
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	existing := filepath.Join(dest, "main.go")
+	if err := ioutil.WriteFile(existing, []byte("package old\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		expected = map[string]bool{existing: true, filepath.Join(dest, "go.mod"): false}
+		got      = make(map[string]bool)
+	)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, DryRun: true}
+	p.Preview = func(path string, exists bool) {
+		got[path] = exists
+	}
+
+	err := p.unzip(context.Background(), newTestZip(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected preview:\n%#+v\nbut got:\n%#+v", expected, got)
+	}
+
+	expectFile(t, existing, "package old\n")
+	if utils.Exists(filepath.Join(dest, "go.mod")) {
+		t.Fatalf("expected go.mod to not be written on dry run")
+	}
+
+	err = p.unzip(context.Background(), newTestZip(t,
+		testFile{"project-master/../evil.go", "package evil\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	if err == nil || !strings.Contains(err.Error(), "illegal path") {
+		t.Fatalf("expected an illegal path error on dry run but got: %v", err)
+	}
+}
+
+func TestProjectUnzipCaseCollision(t *testing.T) {
+	newZip := func() *zip.Reader {
+		return newTestZip(t,
+			testFile{"project-master/Config.go", "package main\n\n// upper\n"},
+			testFile{"project-master/config.go", "package main\n\n// lower\n"},
+			testFile{"project-master/go.mod", "module github.com/author/project\n"},
+		)
+	}
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest}
+	err := p.unzip(context.Background(), newZip())
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		if !errors.Is(err, ErrCaseCollision) {
+			t.Fatalf("expected a case collision error but got: %v", err)
+		}
+		if utils.Exists(filepath.Join(dest, "go.mod")) {
+			t.Fatalf("expected no files to be extracted")
+		}
+		return
+	}
+
+	// Both files are kept on a case-sensitive file system.
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectFile(t, filepath.Join(dest, "Config.go"), "package main\n\n// upper\n")
+	expectFile(t, filepath.Join(dest, "config.go"), "package main\n\n// lower\n")
+
+	caseInsensitiveFS = true
+	defer func() { caseInsensitiveFS = false }()
+
+	dest = newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p = &Project{Name: "project", Repo: "author/project", Dest: dest}
+	if err = p.unzip(context.Background(), newZip()); !errors.Is(err, ErrCaseCollision) {
+		t.Fatalf("expected a case collision error but got: %v", err)
+	}
+}
+
+func TestProjectUnzipRewrites(t *testing.T) {
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	type rewrite struct {
+		occurrences int
+		rewritten   bool
+	}
+
+	var (
+		expected = map[string]rewrite{
+			filepath.Join(dest, "main.go"):   {2, true},
+			filepath.Join(dest, "go.mod"):    {1, true},
+			filepath.Join(dest, "README.md"): {1, false},
+			filepath.Join(dest, "logo.png"):  {1, false},
+		}
+		got = make(map[string]rewrite)
+	)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, Module: "github.com/me/app", DryRun: true}
+	p.Rewrites = func(path string, occurrences int, rewritten bool) {
+		got[path] = rewrite{occurrences, rewritten}
+	}
+
+	err := p.unzip(context.Background(), newTestZip(t,
+		testFile{"project-master/main.go", "package main\n\nimport (\n\t_ \"github.com/author/project/a\"\n\t_ \"github.com/author/project/b\"\n)\n"},
+		testFile{"project-master/sub/sub.go", "package sub\n"},
+		testFile{"project-master/README.md", "go get github.com/author/project\n"},
+		testFile{"project-master/logo.png", "\x89PNG\x00github.com/author/project"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected rewrites:\n%#+v\nbut got:\n%#+v", expected, got)
+	}
+
+	if utils.Exists(filepath.Join(dest, "main.go")) {
+		t.Fatalf("expected main.go to not be written on dry run")
+	}
+}
+
+func TestProjectUnzipOverwrite(t *testing.T) {
+	newZip := func() *zip.Reader {
+		return newTestZip(t,
+			testFile{"project-master/main.go", "package main\n"},
+			testFile{"project-master/go.mod", "module github.com/author/project\n"},
+		)
+	}
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	existing := filepath.Join(dest, "main.go")
+	if err := ioutil.WriteFile(existing, []byte("package old\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest}
+	if err := p.unzip(context.Background(), newZip()); err == nil {
+		t.Fatalf("expected an error for existing files")
+	}
+	if utils.Exists(filepath.Join(dest, "go.mod")) {
+		t.Fatalf("expected nothing to be written when files already exist")
+	}
+
+	p.Overwrite = OverwriteSkip
+	if err := p.unzip(context.Background(), newZip()); err != nil {
+		t.Fatal(err)
+	}
+	expectFile(t, existing, "package old\n")
+	expectFile(t, filepath.Join(dest, "go.mod"), "module github.com/author/project\n")
+
+	p.Overwrite = OverwriteForce
+	if err := p.unzip(context.Background(), newZip()); err != nil {
+		t.Fatal(err)
+	}
+	expectFile(t, existing, "package main\n")
+}
+
+func TestProjectInstallTidy(t *testing.T) {
+	repo, closeProvider := newTestProvider(t, newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n\nfunc main() {}\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := New("project", repo)
+	p.Dest = dest
+	p.Tidy = true
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dest, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(b, []byte("\ngo ")) {
+		t.Fatalf("expected go mod tidy to add the go directive but got:\n%s", b)
+	}
+}
+
+func TestProjectInstallReplaces(t *testing.T) {
+	repo, closeProvider := newTestProvider(t, newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n\nfunc main() {}\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n\nreplace github.com/kataras/neffos => ../neffos\n"},
+	))
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := New("project", repo)
+	p.Dest = dest
+	p.Module = "github.com/me/app"
+	p.Replaces = map[string]string{
+		"github.com/kataras/neffos":   "../fork/neffos",
+		"github.com/kataras/iris/v12": "example.com/mirror/iris/v12 v12.2.0",
+	}
+	installed, err := p.Installation(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "module github.com/me/app\n\nreplace github.com/kataras/neffos => ../fork/neffos\n\nreplace github.com/kataras/iris/v12 => example.com/mirror/iris/v12 v12.2.0\n"
+	expectFile(t, filepath.Join(dest, "go.mod"), expected)
+	if got := installed.Installed["go.mod"]; got != sha256Hex(expected) {
+		t.Fatalf("expected the digest of the edited go.mod but got: %s", got)
+	}
+
+	m, err := ReadManifest(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p.Replaces, m.Replaces) {
+		t.Fatalf("expected the replaces to be recorded but got: %v", m.Replaces)
+	}
+
+	p.Replaces = map[string]string{"github.com/kataras/neffos": ""}
+	if err = p.Validate(); err == nil {
+		t.Fatalf("expected an invalid replace error")
+	}
+}
+
+func TestProjectInstallGitInit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		defer os.Setenv(key, os.Getenv(key))
+		os.Setenv(key, "iris-cli")
+	}
+
+	repo, closeProvider := newTestProvider(t, newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n\nfunc main() {}\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := New("project", repo)
+	p.Dest = dest
+	p.GitInit = true
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command("git", "-C", dest, "ls-files").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := ManifestFilename+"\ngo.mod\nmain.go\n", string(out); expected != got {
+		t.Fatalf("expected committed files:\n%s\nbut got:\n%s", expected, got)
+	}
+}
+
+func TestProjectValidate(t *testing.T) {
+	tests := []struct {
+		project Project
+		valid   bool
+	}{
+		{Project{Repo: "kataras/iris"}, true},
+		{Project{Repo: "github.com/kataras/iris", Module: "github.com/author/app"}, true},
+		{Project{Repo: "gitlab.com/group/subgroup/project"}, true},
+		{Project{Repo: "iris"}, false},
+		{Project{Repo: "kataras/iris/"}, true},
+		{Project{Repo: "https://"}, false},
+		{Project{Repo: "kataras/iris/extra"}, false},
+		{Project{Repo: "kataras/iris", Module: "my app"}, false},
+		{Project{Repo: "kataras/iris", Subdir: "_examples/mvc"}, true},
+		{Project{Repo: "kataras/iris", Subdir: "../other"}, false},
+		{Project{Repo: "kataras/iris", Subdir: "/abs"}, false},
+		{Project{Repo: "kataras/iris", Include: []string{"*.go"}, Exclude: []string{".github"}}, true},
+		{Project{Repo: "kataras/iris", Exclude: []string{"[docs"}}, false},
+	}
+
+	for _, tt := range tests {
+		p := tt.project
+		if err := p.Validate(); tt.valid != (err == nil) {
+			t.Fatalf("[%s] expected valid: %v but got error: %v", p.Repo, tt.valid, err)
+		}
+
+		if p.Version != "master" {
+			t.Fatalf("[%s] expected default version but got %s", p.Repo, p.Version)
+		}
+	}
+}
+
+func TestNormalizeRepo(t *testing.T) {
+	tests := []struct {
+		repo     string
+		expected string
+	}{
+		{"kataras/iris", "kataras/iris"},
+		{"github.com/kataras/iris", "github.com/kataras/iris"},
+		{"https://github.com/kataras/iris", "github.com/kataras/iris"},
+		{"http://github.com/kataras/iris/", "github.com/kataras/iris"},
+		{"https://github.com/kataras/iris.git", "github.com/kataras/iris"},
+		{"github.com/kataras/iris.git/", "github.com/kataras/iris"},
+		{"/kataras/iris/", "kataras/iris"},
+		{"  https://gitlab.com/group/subgroup/project  ", "gitlab.com/group/subgroup/project"},
+		{"github.com/kataras/iris.github.io", "github.com/kataras/iris.github.io"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeRepo(tt.repo); tt.expected != got {
+			t.Fatalf("[%s] expected: %s but got: %s", tt.repo, tt.expected, got)
+		}
+	}
+}
+
+func TestResolveDest(t *testing.T) {
+	defer os.Setenv("GOPATH", os.Getenv("GOPATH"))
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gopath := filepath.Join(wd, "gopath")
+
+	tests := []struct {
+		gopath     string
+		gopathMode bool
+		dest       string
+		module     string
+		expected   string
+	}{
+		{"", false, "", "github.com/org/app", filepath.Join(wd, "app")},
+		{"", false, "", "github.com/org/group/app", filepath.Join(wd, "app")},
+		{"", false, "", "github.com/org/group/app/v2", filepath.Join(wd, "app")},
+		{"", false, "./custom", "github.com/org/group/app", filepath.Join(wd, "custom")},
+		// The GOPATH environment variable is ignored in modules mode.
+		{gopath, false, "", "github.com/org/app", filepath.Join(wd, "app")},
+		{gopath, false, "./custom", "github.com/org/group/app", filepath.Join(wd, "custom")},
+		// GOPATH mode.
+		{gopath, true, "", "github.com/org/app", filepath.Join(gopath, "src", "github.com", "org", "app")},
+		{gopath, true, "", "github.com/org/group/app", filepath.Join(gopath, "src", "github.com", "org", "group", "app")},
+		{gopath, true, "", "github.com/org/group/app/v2", filepath.Join(gopath, "src", "github.com", "org", "group", "app", "v2")},
+		{gopath + string(filepath.ListSeparator) + wd, true, "", "github.com/org/app", filepath.Join(gopath, "src", "github.com", "org", "app")},
+		{gopath, true, "./custom", "github.com/org/group/app", filepath.Join(wd, "custom")},
+		{"", true, "", "github.com/org/app", filepath.Join(build.Default.GOPATH, "src", "github.com", "org", "app")},
+	}
+
+	for i, tt := range tests {
+		os.Setenv("GOPATH", tt.gopath)
+		if got := resolveDest(tt.dest, tt.module, tt.gopathMode); tt.expected != got {
+			t.Fatalf("[%d] expected destination: %s but got %s", i, tt.expected, got)
+		}
+	}
+}
+
+func TestProjectUnzipLogf(t *testing.T) {
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	var logs []string
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, Module: "github.com/me/app"}
+	p.Logf = func(format string, args ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	}
+
+	err := p.unzip(context.Background(), newTestZip(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"detected module <github.com/author/project> from <project-master/go.mod>",
+		"module <github.com/author/project> is replaced with <github.com/me/app>",
+		"destination resolved to <" + dest + ">",
+		"extract <project-master/main.go> to <" + filepath.Join(dest, "main.go") + ">",
+		"extract <project-master/go.mod> to <" + filepath.Join(dest, "go.mod") + ">",
+	}
+	if !reflect.DeepEqual(expected, logs) {
+		t.Fatalf("expected logs:\n%s\nbut got:\n%s", strings.Join(expected, "\n"), strings.Join(logs, "\n"))
+	}
+}
+
+func TestProjectUnzipMaxSize(t *testing.T) {
+	newZip := func() *zip.Reader {
+		return newTestZip(t,
+			testFile{"project-master/main.go", "package main\n"},
+			testFile{"project-master/go.mod", "module github.com/author/project\n"},
+		)
+	}
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := &Project{Name: "project", Repo: "author/project", Dest: dest, MaxSize: 20}
+	if err := p.unzip(context.Background(), newZip()); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected error: %v but got: %v", ErrTooLarge, err)
+	}
+	if utils.Exists(filepath.Join(dest, "main.go")) {
+		t.Fatalf("expected nothing to be written when the limit is exceeded")
+	}
+
+	p.MaxSize = -1
+	if err := p.unzip(context.Background(), newZip()); err != nil {
+		t.Fatal(err)
+	}
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n")
+}
+
+func TestProjectInstallTarGzMaxSize(t *testing.T) {
+	archive := filepath.Join(newTestDest(t), "project.tar.gz")
+	defer os.RemoveAll(filepath.Dir(archive))
+
+	// Highly compressible, like a zip bomb.
+	contents := strings.Repeat("0", 1<<20)
+	if err := ioutil.WriteFile(archive, newTestTarGz(t,
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+		testFile{"project-master/bomb.txt", contents},
+	), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := NewFromArchive(dest, archive)
+	p.MaxSize = 1 << 10
+	// The conversion to zip stops, before the extraction's size check.
+	if err := p.Install(); !errors.Is(err, ErrTooLarge) || !strings.Contains(err.Error(), "archive <"+archive+">") {
+		t.Fatalf("expected error: %v of the archive but got: %v", ErrTooLarge, err)
+	}
+
+	p.MaxSize = -1
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+	expectFile(t, filepath.Join(dest, "bomb.txt"), contents)
+}
+
+func TestProjectInstallationReuse(t *testing.T) {
+	repo, closeProvider := newTestProvider(t, newTestArchive(t,
+		testFile{"project-master/main.go", "package main\n"},
+		testFile{"project-master/go.mod", "module github.com/author/project\n"},
+	))
+	defer closeProvider()
+
+	p := New("project", repo)
+	p.NoCache = true
+	template := *p
+
+	dests := []string{newTestDest(t), newTestDest(t)}
+	installed := make([]*Project, len(dests))
+	errs := make([]error, len(dests))
+
+	var wg sync.WaitGroup
+	for i := range dests {
+		defer os.RemoveAll(dests[i])
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			c := *p
+			c.Dest = dests[i]
+			installed[i], errs[i] = c.Installation(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	for i, dest := range dests {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+
+		if expected, got := dest, installed[i].Dest; expected != got {
+			t.Fatalf("[%d] expected destination: %s but got: %s", i, expected, got)
+		}
+
+		if expected, got := "github.com/author/project", installed[i].Module; expected != got {
+			t.Fatalf("[%d] expected module: %s but got: %s", i, expected, got)
+		}
+
+		if expected, got := 2, len(installed[i].Installed); expected != got {
+			t.Fatalf("[%d] expected %d installed files but got %d", i, expected, got)
+		}
+		expectFile(t, filepath.Join(dest, "main.go"), "package main\n")
+	}
+
+	// The same project is installed again to another destination.
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p.Dest = dest
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+	p.Dest = ""
+
+	if !reflect.DeepEqual(&template, p) {
+		t.Fatalf("expected the project to not be modified by the installations:\n%#+v\nbut got:\n%#+v", template, *p)
+	}
+}
+
+func TestProjectInstallResult(t *testing.T) {
+	body := newTestArchive(t,
+		testFile{"project-master/README.md", "# project\n"},
+		testFile{"project-master/backend/main.go", "package main\n"},
+		testFile{"project-master/backend/go.mod", "module github.com/author/project/backend\n"},
+	)
+	repo, closeProvider := newTestProvider(t, body)
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := New("project", repo)
+	p.Dest = dest
+	result, err := p.InstallResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &InstallResult{
+		ResolvedModule: "github.com/author/project/backend",
+		ResolvedDest:   dest,
+		ProjectDir:     filepath.Join(dest, "backend"),
+		Files: []string{
+			filepath.Join(dest, "README.md"),
+			filepath.Join(dest, "backend", "go.mod"),
+			filepath.Join(dest, "backend", "main.go"),
+		},
+		ArchiveChecksum: sha256Hex(string(body)),
+	}
+
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("expected result:\n%#+v\nbut got:\n%#+v", expected, result)
+	}
+
+	if p.Module != "" || p.ModuleDir != "" {
+		t.Fatalf("expected the project to not be modified")
+	}
+}
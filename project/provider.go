@@ -0,0 +1,214 @@
+package project
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// Provider describes a git repository host which serves downloadable archives.
+type Provider struct {
+	// Host is the repository's host prefix, e.g. "github.com".
+	Host string
+	// ArchiveURL returns the archive URL of "repo" (without the host, e.g. "kataras/iris") at "version".
+	// The archive can be a zip or a tar.gz one, e.g. of a tarball endpoint, see `Format`.
+	// The "version" is not escaped, it may contain slashes, e.g. a "feature/login" branch, see `url.PathEscape`.
+	ArchiveURL func(repo, version string) string
+	// Format is the format of the archives of both the `ArchiveURL` and the `FallbackArchiveURL`,
+	// e.g. `FormatZip`. If empty then it's detected by the downloaded archive's contents.
+	Format ArchiveFormat
+	// FallbackArchiveURL, if not nil, returns the archive URL which is downloaded when the `ArchiveURL` one fails,
+	// e.g. when the "version" was guessed to be a branch but it's a tag.
+	FallbackArchiveURL func(repo, version string) string
+	// RefsURLs returns the API URLs of the "repo" branches and tags, e.g. for `Project.RemoteRefs`.
+	// Each URL should respond with a JSON array of objects with a "name" field,
+	// the next pages, if any, are followed through the "Link" response header (rel="next").
+	RefsURLs func(repo string) []string
+	// DefaultBranchURL, if not nil, returns the API URL of the "repo", e.g. for `Project.DefaultBranch`.
+	// It should respond with a JSON object with a "default_branch" field.
+	DefaultBranchURL func(repo string) string
+	// Authorize sets the "token" to the request, used to download private repositories.
+	// If nil then the "Authorization: token $token" header is set.
+	Authorize func(r *http.Request, token string)
+}
+
+var (
+	// GitHub is the github.com provider.
+	// It's the default one when a repository has no host or its host is unknown.
+	// The archives are downloaded from codeload.github.com directly, instead of through a redirect,
+	// the version is a tag if it looks like one, e.g. "v1.2.3", a commit if it looks like a SHA
+	// or a branch otherwise. On failure the github.com archive URL, which resolves any ref, is used instead.
+	GitHub = &Provider{
+		Host: "github.com",
+		ArchiveURL: func(repo, version string) string {
+			ref := "refs/heads/" + escapeRef(version)
+			if isCommitSHA(version) {
+				ref = version
+			} else if isTagVersion(version) {
+				ref = "refs/tags/" + escapeRef(version)
+			}
+
+			return fmt.Sprintf("https://codeload.github.com/%s/zip/%s", repo, ref)
+		},
+		Format: FormatZip,
+		FallbackArchiveURL: func(repo, version string) string {
+			return fmt.Sprintf("https://github.com/%s/archive/%s.zip", repo, url.PathEscape(version))
+		},
+		RefsURLs: func(repo string) []string {
+			return []string{
+				fmt.Sprintf("https://api.github.com/repos/%s/branches?per_page=100", repo),
+				fmt.Sprintf("https://api.github.com/repos/%s/tags?per_page=100", repo),
+			}
+		},
+		DefaultBranchURL: func(repo string) string {
+			return fmt.Sprintf("https://api.github.com/repos/%s", repo)
+		},
+	}
+	// GitLab is the gitlab.com provider.
+	GitLab = &Provider{
+		Host: "gitlab.com",
+		ArchiveURL: func(repo, version string) string {
+			// The filename is informational, GitLab names the archives of "a/b" refs as "project-a-b".
+			return fmt.Sprintf("https://gitlab.com/%s/-/archive/%s/%s-%s.zip", repo, url.PathEscape(version), path.Base(repo), strings.Replace(version, "/", "-", -1))
+		},
+		Format: FormatZip,
+		RefsURLs: func(repo string) []string {
+			id := url.PathEscape(repo)
+			return []string{
+				fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/repository/branches?per_page=100", id),
+				fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/repository/tags?per_page=100", id),
+			}
+		},
+		DefaultBranchURL: func(repo string) string {
+			return fmt.Sprintf("https://gitlab.com/api/v4/projects/%s", url.PathEscape(repo))
+		},
+		Authorize: func(r *http.Request, token string) {
+			r.Header.Set("PRIVATE-TOKEN", token)
+		},
+	}
+	// Bitbucket is the bitbucket.org provider.
+	Bitbucket = &Provider{
+		Host: "bitbucket.org",
+		ArchiveURL: func(repo, version string) string {
+			return fmt.Sprintf("https://bitbucket.org/%s/get/%s.zip", repo, url.PathEscape(version))
+		},
+		Format: FormatZip,
+		Authorize: func(r *http.Request, token string) {
+			r.Header.Set("Authorization", "Bearer "+token)
+		},
+	}
+)
+
+var providers = []*Provider{GitHub, GitLab, Bitbucket}
+
+// RegisterProvider registers a provider, e.g. for a self-hosted repository host,
+// so the repositories with its `Host` prefix are downloaded through it.
+// A registered provider with the same host is replaced.
+// It should be called before any installation.
+func RegisterProvider(provider *Provider) {
+	for i, p := range providers {
+		if p.Host == provider.Host {
+			providers[i] = provider
+			return
+		}
+	}
+
+	providers = append(providers, provider)
+}
+
+// GitHubEnterprise returns a provider of a GitHub Enterprise Server on "host", e.g. "ghe.mycorp.com",
+// which downloads the archives through its REST API, so a token can be used for private repositories.
+// Use it with `RegisterProvider`.
+func GitHubEnterprise(host string) *Provider {
+	api := "https://" + host + "/api/v3/repos/"
+	return &Provider{
+		Host: host,
+		ArchiveURL: func(repo, version string) string {
+			return api + repo + "/zipball/" + url.PathEscape(version)
+		},
+		Format: FormatZip,
+		RefsURLs: func(repo string) []string {
+			return []string{api + repo + "/branches?per_page=100", api + repo + "/tags?per_page=100"}
+		},
+		DefaultBranchURL: func(repo string) string {
+			return api + repo
+		},
+	}
+}
+
+// ProviderOf returns the provider of "repo" and the repository's path without its host,
+// e.g. "gitlab.com/group/project" returns the `GitLab` provider and "group/project".
+func ProviderOf(repo string) (*Provider, string) {
+	for _, p := range providers {
+		if prefix := p.Host + "/"; strings.HasPrefix(repo, prefix) {
+			return p, strings.TrimPrefix(repo, prefix)
+		}
+	}
+
+	return GitHub, repo
+}
+
+// escapeRef escapes the path elements of a git "ref", e.g. a "feature/login" branch, keeping its slashes.
+func escapeRef(ref string) string {
+	elems := strings.Split(ref, "/")
+	for i, elem := range elems {
+		elems[i] = url.PathEscape(elem)
+	}
+
+	return strings.Join(elems, "/")
+}
+
+// isTagVersion reports whether the "version" looks like a release tag:
+// dot-separated numbers with an optional "v" prefix and pre-release or build suffix, e.g. "v1.2.3", "v12" or "1.0.0-rc.1".
+func isTagVersion(version string) bool {
+	v := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(v, "-+"); i > 0 {
+		v = v[:i]
+	}
+
+	for _, n := range strings.Split(v, ".") {
+		if n == "" {
+			return false
+		}
+
+		for _, r := range n {
+			if r < '0' || r > '9' {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isCommitSHA reports whether the "version" looks like an, abbreviated or full, commit SHA.
+func isCommitSHA(version string) bool {
+	if len(version) < 7 || len(version) > 40 {
+		return false
+	}
+
+	for _, r := range version {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// authorize returns a download option which sets the "token" to the request.
+func (p *Provider) authorize(token string) utils.DownloadOption {
+	return func(r *http.Request) error {
+		if p.Authorize != nil {
+			p.Authorize(r, token)
+		} else {
+			r.Header.Set("Authorization", "token "+token)
+		}
+
+		return nil
+	}
+}
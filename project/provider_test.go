@@ -0,0 +1,117 @@
+package project
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestProviderArchiveURL(t *testing.T) {
+	tests := []struct {
+		repo     string
+		version  string
+		expected string
+	}{
+		{"kataras/iris", "master", "https://codeload.github.com/kataras/iris/zip/refs/heads/master"},
+		{"github.com/kataras/iris", "v12.1.2", "https://codeload.github.com/kataras/iris/zip/refs/tags/v12.1.2"},
+		{"kataras/iris", "1.0.0-rc.1", "https://codeload.github.com/kataras/iris/zip/refs/tags/1.0.0-rc.1"},
+		{"kataras/iris", "v12.x", "https://codeload.github.com/kataras/iris/zip/refs/heads/v12.x"},
+		{"kataras/iris", "3f2a9c1", "https://codeload.github.com/kataras/iris/zip/3f2a9c1"},
+		{"gitlab.com/group/project", "main", "https://gitlab.com/group/project/-/archive/main/project-main.zip"},
+		{"bitbucket.org/owner/project", "master", "https://bitbucket.org/owner/project/get/master.zip"},
+		{"kataras/iris", "feature/login", "https://codeload.github.com/kataras/iris/zip/refs/heads/feature/login"},
+		{"gitlab.com/group/project", "feature/login", "https://gitlab.com/group/project/-/archive/feature%2Flogin/project-feature-login.zip"},
+		{"bitbucket.org/owner/project", "feature/login", "https://bitbucket.org/owner/project/get/feature%2Flogin.zip"},
+	}
+
+	for i, tt := range tests {
+		provider, repo := ProviderOf(tt.repo)
+		if got := provider.ArchiveURL(repo, tt.version); tt.expected != got {
+			t.Fatalf("[%d] expected archive URL: %s but got %s", i, tt.expected, got)
+		}
+	}
+}
+
+func TestProjectToken(t *testing.T) {
+	for key, value := range map[string]string{"GITHUB_TOKEN": "secret", "IRIS_CLI_TOKEN": ""} {
+		defer os.Setenv(key, os.Getenv(key))
+		os.Setenv(key, value)
+	}
+
+	p := New("project", "author/project")
+	for provider, expected := range map[*Provider]string{GitHub: "secret", GitLab: "", Bitbucket: ""} {
+		if got := p.token(provider); expected != got {
+			t.Fatalf("[%s] expected token: %q but got: %q", provider.Host, expected, got)
+		}
+	}
+
+	os.Setenv("IRIS_CLI_TOKEN", "other")
+	if expected, got := "other", p.token(GitLab); expected != got {
+		t.Fatalf("expected token: %q but got: %q", expected, got)
+	}
+}
+
+func TestProviderFallbackArchiveURL(t *testing.T) {
+	if expected, got := "https://github.com/kataras/iris/archive/feature%2Flogin.zip", GitHub.FallbackArchiveURL("kataras/iris", "feature/login"); expected != got {
+		t.Fatalf("expected fallback archive URL: %s but got %s", expected, got)
+	}
+}
+
+func TestProviderFormat(t *testing.T) {
+	// The format of each built-in provider matches its archive URLs.
+	extensions := map[ArchiveFormat][]string{
+		FormatZip:   {".zip", "/zip/", "/zipball/"},
+		FormatTarGz: {".tar.gz", "/tar.gz/", "/tarball/"},
+	}
+
+	for _, provider := range []*Provider{GitHub, GitLab, Bitbucket, GitHubEnterprise("ghe.mycorp.com")} {
+		matches, ok := extensions[provider.Format]
+		if !ok {
+			t.Fatalf("[%s] expected a declared archive format but got: %q", provider.Host, provider.Format)
+		}
+
+		urls := []string{provider.ArchiveURL("author/project", "v1.0.0"), provider.ArchiveURL("author/project", "master")}
+		if provider.FallbackArchiveURL != nil {
+			urls = append(urls, provider.FallbackArchiveURL("author/project", "v1.0.0"))
+		}
+
+		for _, u := range urls {
+			if !containsAny(u, matches) {
+				t.Fatalf("[%s] expected a %s archive URL but got: %s", provider.Host, provider.Format, u)
+			}
+		}
+	}
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestRegisterProvider(t *testing.T) {
+	RegisterProvider(GitHubEnterprise("ghe.mycorp.com"))
+	defer func() {
+		providers = providers[0 : len(providers)-1]
+	}()
+
+	provider, repo := ProviderOf("ghe.mycorp.com/team/app")
+	if expected, got := "https://ghe.mycorp.com/api/v3/repos/team/app/zipball/v1.0.0", provider.ArchiveURL(repo, "v1.0.0"); expected != got {
+		t.Fatalf("expected archive URL: %s but got %s", expected, got)
+	}
+
+	// Same host replaces the registered provider.
+	n := len(providers)
+	RegisterProvider(GitHubEnterprise("ghe.mycorp.com"))
+	if len(providers) != n {
+		t.Fatalf("expected %d providers but got %d", n, len(providers))
+	}
+
+	if provider, _ = ProviderOf("github.com/kataras/iris"); provider != GitHub {
+		t.Fatalf("expected the GitHub provider")
+	}
+}
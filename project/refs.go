@@ -0,0 +1,108 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// refsCacheTTL is the duration which the results of `RemoteRefs` are cached for,
+// so an interactive prompt which re-renders does not hit the API rate limits.
+const refsCacheTTL = time.Minute
+
+type cachedRefs struct {
+	refs    []string
+	expires time.Time
+}
+
+var (
+	refsCache   = make(map[string]cachedRefs) // key = repo.
+	refsCacheMu sync.Mutex
+)
+
+// maxRefsPages is the limit of the pages of each `Provider.RefsURLs` which `RemoteRefs` follows.
+const maxRefsPages = 100
+
+// RemoteRefs returns the available branch and tag names of the project's repository,
+// e.g. to let the user choose a `Version`. All the pages of the provider's API are read.
+func (p *Project) RemoteRefs() ([]string, error) {
+	refsCacheMu.Lock()
+	cached, ok := refsCache[p.Repo]
+	refsCacheMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return append([]string(nil), cached.refs...), nil // the cache is not modified by the caller.
+	}
+
+	provider, repo := ProviderOf(p.Repo)
+	if provider.RefsURLs == nil {
+		return nil, fmt.Errorf("listing the references of repository <%s> is not supported", p.Repo)
+	}
+
+	var refs []string
+	for _, refsURL := range provider.RefsURLs(repo) {
+		for page := 0; refsURL != "" && page < maxRefsPages; page++ {
+			names, next, err := p.refsPage(provider, refsURL)
+			if err != nil {
+				return nil, err
+			}
+
+			refs = append(refs, names...)
+			refsURL = next
+		}
+	}
+
+	refsCacheMu.Lock()
+	refsCache[p.Repo] = cachedRefs{refs: append([]string(nil), refs...), expires: time.Now().Add(refsCacheTTL)}
+	refsCacheMu.Unlock()
+
+	return refs, nil
+}
+
+// refsPage returns the reference names of the "refsURL" page and the URL of the next page, if any.
+func (p *Project) refsPage(provider *Provider, refsURL string) ([]string, string, error) {
+	r, err := utils.DownloadReaderContext(context.Background(), p.httpClient(), refsURL, nil, p.downloadOptions(provider)...)
+	if err != nil {
+		return nil, "", p.downloadError(err)
+	}
+	defer r.Close()
+
+	var resp []struct {
+		Name string `json:"name"`
+	}
+	if err = json.NewDecoder(r).Decode(&resp); err != nil {
+		return nil, "", err
+	}
+
+	names := make([]string, 0, len(resp))
+	for _, ref := range resp {
+		names = append(names, ref.Name)
+	}
+
+	return names, nextPageURL(utils.ResponseHeader(r)), nil
+}
+
+// nextPageURL returns the URL of the "next" relation of the "Link" header, if any,
+// e.g. `<https://api.github.com/repositories/1/branches?page=2>; rel="next"`.
+func nextPageURL(header http.Header) string {
+	for _, link := range strings.Split(header.Get("Link"), ",") {
+		parts := strings.Split(link, ";")
+		target := strings.TrimSpace(parts[0])
+		if len(target) < 2 || target[0] != '<' || target[len(target)-1] != '>' {
+			continue
+		}
+
+		for _, param := range parts[1:] {
+			if strings.Replace(strings.TrimSpace(param), " ", "", -1) == `rel="next"` {
+				return target[1 : len(target)-1]
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,95 @@
+package project
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestProjectRemoteRefs(t *testing.T) {
+	var requests int
+	repo, closeProvider := newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/author/project/branches":
+			w.Write([]byte(`[{"name":"master"},{"name":"dev"}]`))
+		case "/author/project/tags":
+			w.Write([]byte(`[{"name":"v1.0.0"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer closeProvider()
+
+	provider, _ := ProviderOf(repo)
+	provider.RefsURLs = func(repo string) []string {
+		base := provider.ArchiveURL(repo, "")
+		base = base[:len(base)-len("/.zip")]
+		return []string{base + "/branches", base + "/tags"}
+	}
+
+	expected := []string{"master", "dev", "v1.0.0"}
+	for i := 0; i < 2; i++ {
+		refs, err := New("project", repo).RemoteRefs()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(expected, refs) {
+			t.Fatalf("expected refs: %v but got %v", expected, refs)
+		}
+	}
+
+	if expected, got := 2, requests; expected != got {
+		t.Fatalf("expected %d requests, the second call should be cached, but got %d", expected, got)
+	}
+}
+
+func TestProjectRemoteRefsPages(t *testing.T) {
+	var base string
+	repo, closeProvider := newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case "/author/project/branches?":
+			w.Header().Set("Link", `<`+base+`/branches?page=2>; rel="next", <`+base+`/branches?page=3>; rel="last"`)
+			w.Write([]byte(`[{"name":"master"}]`))
+		case "/author/project/branches?page=2":
+			w.Header().Set("Link", `<`+base+`/branches?page=3>; rel="next", <`+base+`/branches>; rel="first"`)
+			w.Write([]byte(`[{"name":"dev"}]`))
+		case "/author/project/branches?page=3":
+			w.Header().Set("Link", `<`+base+`/branches>; rel="first"`)
+			w.Write([]byte(`[{"name":"feature"}]`))
+		case "/author/project/tags?":
+			w.Write([]byte(`[{"name":"v1.0.0"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer closeProvider()
+
+	provider, _ := ProviderOf(repo)
+	provider.RefsURLs = func(repo string) []string {
+		base = provider.ArchiveURL(repo, "")
+		base = base[:len(base)-len("/.zip")]
+		return []string{base + "/branches", base + "/tags"}
+	}
+
+	expected := []string{"master", "dev", "feature", "v1.0.0"}
+	refs, err := New("project", repo).RemoteRefs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, refs) {
+		t.Fatalf("expected refs: %v but got %v", expected, refs)
+	}
+
+	// The cached refs are not modified by the caller.
+	refs[0] = "modified"
+	if refs, err = New("project", repo).RemoteRefs(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, refs) {
+		t.Fatalf("expected cached refs: %v but got %v", expected, refs)
+	}
+}
@@ -1,6 +1,7 @@
 package project
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -90,19 +91,33 @@ func (r *Registry) Exists(name string) (string, bool) {
 
 // Install downloads and unzips a project with "name" to "dest" as "module".
 func (r *Registry) Install(p *Project) error {
+	_, err := r.Installation(p)
+	return err
+}
+
+// Installation same as `Install` but it returns the installed project with its resolved values,
+// the "p" is not modified, see `Project.Installation`.
+func (r *Registry) Installation(p *Project) (*Project, error) {
+	return r.InstallationContext(context.Background(), p)
+}
+
+// InstallationContext same as `Installation` but it accepts a context which can cancel the installation,
+// see `Project.InstallContext`.
+func (r *Registry) InstallationContext(ctx context.Context, p *Project) (*Project, error) {
 	for projectName, repo := range r.Projects {
 		if projectName != p.Name {
 			continue
 		}
 
-		p.Repo = repo
-
-		err := p.Install()
-		if err == nil {
-			r.installed[projectName] = struct{}{}
+		installed := *p
+		installed.Repo = repo
+		if _, err := installed.install(ctx); err != nil {
+			return nil, err
 		}
-		return err
+
+		r.installed[projectName] = struct{}{}
+		return &installed, nil
 	}
 
-	return ErrProjectNotExists
+	return nil, ErrProjectNotExists
 }
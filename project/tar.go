@@ -0,0 +1,175 @@
+package project
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	// tarMagic is the "ustar" magic of a tar header, at the tarMagicOffset.
+	tarMagic       = []byte("ustar")
+	tarMagicOffset = 257
+)
+
+// ArchiveFormat is the format of a project's archive, see `Provider.Format`.
+type ArchiveFormat string
+
+const (
+	// FormatDetect detects the format of an archive by its contents, e.g. of a local one.
+	FormatDetect ArchiveFormat = ""
+	// FormatZip is the format of the zip archives.
+	FormatZip ArchiveFormat = "zip"
+	// FormatTar is the format of the uncompressed tar archives.
+	FormatTar ArchiveFormat = "tar"
+	// FormatTarGz is the format of the gzip-compressed tar archives, e.g. of the tarball endpoints.
+	FormatTarGz ArchiveFormat = "tar.gz"
+)
+
+// zipArchive returns the "archive" file if it's a zip one, otherwise, if it's a tar or a tar.gz one,
+// it returns a temporary zip file with its entries and a function which removes it.
+// So the extraction, e.g. the module name replacement and the path checks, is shared by all formats.
+// The "format" is detected by the archive's contents if it's `FormatDetect`.
+// The conversion fails with an `ErrTooLarge` once the entries exceed the "maxSize" bytes, unless it's negative.
+func zipArchive(archive string, format ArchiveFormat, maxSize int64) (string, func(), error) {
+	switch format {
+	case FormatZip:
+		return archive, func() {}, nil
+	case FormatDetect, FormatTar, FormatTarGz:
+	default:
+		return "", nil, fmt.Errorf("archive <%s>: unknown format <%s>", archive, format)
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	var (
+		r       = bufio.NewReader(f)
+		gzipped = format == FormatTarGz
+	)
+
+	if format == FormatDetect {
+		magic, _ := r.Peek(len(gzipMagic))
+		gzipped = bytes.Equal(magic, gzipMagic)
+	}
+
+	var tr io.Reader = r
+	if gzipped {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return "", nil, fmt.Errorf("archive <%s>: %w", archive, err)
+		}
+		defer gr.Close()
+
+		tr = gr
+	}
+
+	br := bufio.NewReader(tr)
+	if format == FormatDetect {
+		if header, _ := br.Peek(tarMagicOffset + len(tarMagic)); len(header) < tarMagicOffset+len(tarMagic) ||
+			!bytes.Equal(header[tarMagicOffset:], tarMagic) {
+			if gzipped {
+				return "", nil, fmt.Errorf("archive <%s> is a gzip file but not a tar.gz one", archive)
+			}
+
+			return archive, func() {}, nil // let the zip reader decide.
+		}
+	}
+
+	zipFile, err := tarToZip(br, maxSize)
+	if err != nil {
+		return "", nil, fmt.Errorf("archive <%s>: %w", archive, err)
+	}
+
+	return zipFile, func() { os.Remove(zipFile) }, nil
+}
+
+// tarToZip writes the entries of the "r" tar stream to a temporary zip file and returns its path.
+// Only directories, regular files and symbolic links are kept.
+// It fails with an `ErrTooLarge` once the contents exceed the "maxSize" bytes, unless it's negative.
+func tarToZip(r io.Reader, maxSize int64) (string, error) {
+	f, err := ioutil.TempFile("", "iris-cli-*.zip")
+	if err != nil {
+		return "", err
+	}
+
+	w := zip.NewWriter(f)
+	tr := tar.NewReader(r)
+	remaining := maxSize
+	for {
+		var hdr *tar.Header
+		if hdr, err = tr.Next(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			break
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir, tar.TypeReg, tar.TypeSymlink:
+		default:
+			continue // e.g. the pax global header of the GitHub tarballs.
+		}
+
+		var h *zip.FileHeader
+		if h, err = zip.FileInfoHeader(hdr.FileInfo()); err != nil {
+			break
+		}
+
+		h.Name = strings.TrimPrefix(hdr.Name, "./")
+		if hdr.Typeflag == tar.TypeDir {
+			if !strings.HasSuffix(h.Name, "/") {
+				h.Name += "/"
+			}
+			if _, err = w.CreateHeader(h); err != nil {
+				break
+			}
+			continue
+		}
+
+		h.Method = zip.Deflate
+		var fw io.Writer
+		if fw, err = w.CreateHeader(h); err != nil {
+			break
+		}
+
+		if hdr.Typeflag == tar.TypeSymlink {
+			_, err = fw.Write([]byte(hdr.Linkname))
+		} else {
+			if err = copyLimited(fw, tr, &remaining); errors.Is(err, ErrTooLarge) {
+				err = fmt.Errorf("%w: more than %d bytes, <%s> exceeds the limit", ErrTooLarge, maxSize, hdr.Name)
+			}
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
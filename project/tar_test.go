@@ -0,0 +1,157 @@
+package project
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// newTestTarGz returns a tar.gz archive of the "files", a name with a trailing slash is a directory
+// and a name with a "@" prefix is a symbolic link to its contents.
+func newTestTarGz(t testing.TB, files ...testFile) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+
+	// Like the GitHub tarballs.
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeXGlobalHeader, Name: "pax_global_header", PAXRecords: map[string]string{"comment": "sha"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range files {
+		hdr := &tar.Header{Name: f.Name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(f.Contents))}
+		switch {
+		case f.Name[len(f.Name)-1] == '/':
+			hdr.Typeflag, hdr.Mode, hdr.Size = tar.TypeDir, 0755, 0
+		case f.Name[0] == '@':
+			hdr.Name, hdr.Typeflag, hdr.Linkname, hdr.Size = f.Name[1:], tar.TypeSymlink, f.Contents, 0
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(f.Contents)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestProjectInstallTarGz(t *testing.T) {
+	files := []testFile{
+		{"author-project-abc123/", ""},
+		{"author-project-abc123/main.go", "package main\n\nimport _ \"github.com/author/project/sub\"\n"},
+		{"author-project-abc123/sub/sub.go", "package sub\n"},
+		{"author-project-abc123/go.mod", "module github.com/author/project\n"},
+	}
+	if runtime.GOOS != "windows" {
+		files = append(files, testFile{"@author-project-abc123/link.go", "main.go"})
+	}
+
+	repo, closeProvider := newTestProvider(t, newTestTarGz(t, files...))
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := New("project", repo)
+	p.Dest = dest
+	p.Module = "github.com/me/app"
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(dest, "main.go"), "package main\n\nimport _ \"github.com/me/app/sub\"\n")
+	expectFile(t, filepath.Join(dest, "sub", "sub.go"), "package sub\n")
+	expectFile(t, filepath.Join(dest, "go.mod"), "module github.com/me/app\n")
+
+	if runtime.GOOS != "windows" {
+		if target, err := os.Readlink(filepath.Join(dest, "link.go")); err != nil || target != "main.go" {
+			t.Fatalf("expected a link to main.go but got: %s (%v)", target, err)
+		}
+	}
+}
+
+func TestProjectInstallLocalTarGz(t *testing.T) {
+	archive, err := ioutil.TempFile("", "starter-*.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(archive.Name())
+
+	_, err = archive.Write(newTestTarGz(t,
+		testFile{"starter/main.go", "package main\n"},
+		testFile{"starter/go.mod", "module github.com/author/starter\n"},
+	))
+	archive.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewFromArchive(newTestDest(t), archive.Name())
+	defer os.RemoveAll(p.Dest)
+
+	if filepath.Ext(p.Name) != "" {
+		t.Fatalf("expected the name without the extensions but got: %s", p.Name)
+	}
+
+	if err = p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectFile(t, filepath.Join(p.Dest, "main.go"), "package main\n")
+}
+
+func TestProjectInstallProviderFormat(t *testing.T) {
+	repo, closeProvider := newTestProvider(t, newTestTarGz(t,
+		testFile{"author-project-abc123/main.go", "package main\n"},
+		testFile{"author-project-abc123/go.mod", "module github.com/author/project\n"},
+	))
+	defer closeProvider()
+
+	provider, _ := ProviderOf(repo)
+	for _, tt := range []struct {
+		format ArchiveFormat
+		ok     bool
+	}{
+		{FormatTarGz, true},
+		{FormatDetect, true},
+		{FormatZip, false}, // not sniffed.
+		{"rar", false},
+	} {
+		provider.Format = tt.format
+
+		dest := newTestDest(t)
+		defer os.RemoveAll(dest)
+
+		p := New("project", repo)
+		p.Dest = dest
+		p.NoCache = true
+		if err := p.Install(); (err == nil) != tt.ok {
+			t.Fatalf("[%s] expected success: %v but got: %v", tt.format, tt.ok, err)
+		}
+
+		if tt.ok {
+			expectFile(t, filepath.Join(dest, "main.go"), "package main\n")
+		}
+	}
+}
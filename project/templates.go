@@ -0,0 +1,86 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// DefaultTemplatesEndpoint is the default location of the curated starter templates index, see `Templates`.
+const DefaultTemplatesEndpoint = "https://raw.githubusercontent.com/iris-contrib/templates/master/index.json"
+
+var (
+	// TemplatesEndpoint is the URL or the local file of the templates index, see `Templates`.
+	TemplatesEndpoint = DefaultTemplatesEndpoint
+	// TemplatesCacheTTL is the duration which the downloaded templates index is cached for.
+	TemplatesCacheTTL = time.Hour
+)
+
+// Templates returns the installable starter templates of the `TemplatesEndpoint` index.
+// The index is a JSON array of projects, e.g.
+// [{"name": "mvc", "repo": "iris-contrib/mvc-starter", "version": "master", "module": "app", "description": "An MVC starter"}].
+// A downloaded index is cached for `TemplatesCacheTTL`, an expired one is used if the download fails.
+func Templates() ([]Project, error) {
+	endpoint := TemplatesEndpoint
+	if !strings.HasPrefix(endpoint, "http") {
+		b, err := ioutil.ReadFile(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		return parseTemplates(b)
+	}
+
+	cacheFile := ""
+	if root, err := userCacheDir(); err == nil {
+		cacheFile = filepath.Join(root, "iris-cli", "templates.json")
+		if info, err := os.Stat(cacheFile); err == nil && time.Since(info.ModTime()) <= TemplatesCacheTTL {
+			if b, err := ioutil.ReadFile(cacheFile); err == nil {
+				if templates, err := parseTemplates(b); err == nil {
+					return templates, nil
+				}
+			}
+		}
+	}
+
+	b, err := utils.DownloadContext(context.Background(), defaultClient, endpoint, nil)
+	if err != nil {
+		if cacheFile != "" {
+			if cached, cacheErr := ioutil.ReadFile(cacheFile); cacheErr == nil {
+				return parseTemplates(cached)
+			}
+		}
+		return nil, err
+	}
+
+	templates, err := parseTemplates(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheFile != "" && os.MkdirAll(filepath.Dir(cacheFile), os.ModePerm) == nil {
+		ioutil.WriteFile(cacheFile, b, 0644)
+	}
+
+	return templates, nil
+}
+
+func parseTemplates(b []byte) ([]Project, error) {
+	var templates []Project
+	if err := json.Unmarshal(b, &templates); err != nil {
+		return nil, err
+	}
+
+	for i := range templates {
+		if templates[i].Version == "" {
+			templates[i].Version = "master"
+		}
+	}
+
+	return templates, nil
+}
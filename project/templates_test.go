@@ -0,0 +1,57 @@
+package project
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTemplates(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`[
+			{"name": "mvc", "repo": "iris-contrib/mvc-starter", "module": "app", "description": "An MVC starter"},
+			{"name": "rest", "repo": "iris-contrib/rest-starter", "version": "v1.0.0"}
+		]`))
+	}))
+
+	defer func(endpoint string) { TemplatesEndpoint = endpoint }(TemplatesEndpoint)
+	TemplatesEndpoint = srv.URL + "/index.json"
+
+	expected := []Project{
+		{Name: "mvc", Repo: "iris-contrib/mvc-starter", Version: "master", Module: "app", Description: "An MVC starter"},
+		{Name: "rest", Repo: "iris-contrib/rest-starter", Version: "v1.0.0"},
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := Templates()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(expected, got) {
+			t.Fatalf("[%d] expected templates:\n%#+v\nbut got:\n%#+v", i, expected, got)
+		}
+	}
+
+	if requests != 1 {
+		t.Fatalf("expected the index to be cached but got %d requests", requests)
+	}
+
+	// Use the expired cache if the download fails.
+	defer func(ttl time.Duration) { TemplatesCacheTTL = ttl }(TemplatesCacheTTL)
+	TemplatesCacheTTL = 0
+	srv.Close()
+
+	got, err := Templates()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected cached templates:\n%#+v\nbut got:\n%#+v", expected, got)
+	}
+}
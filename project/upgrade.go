@@ -0,0 +1,215 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// The upgrade statuses of a file, see `Project.Upgrade`.
+const (
+	// UpgradeAdded is a new file of the upstream project.
+	UpgradeAdded = "added"
+	// UpgradeUpdated is a file which is changed upstream and it's not modified locally.
+	UpgradeUpdated = "updated"
+	// UpgradeRemoved is a file which is removed upstream and it's not modified locally.
+	UpgradeRemoved = "removed"
+	// UpgradeConflict is a file which is changed both upstream and locally, the local file is kept
+	// and the upstream one is written next to it with the ".new" suffix and the base one, of the manifest's version,
+	// with the ".orig" suffix, so they can be merged by hand. If the base file is not available
+	// then the ".orig" one is a copy of the local file.
+	UpgradeConflict = "conflict"
+	// UpgradeKept is a file which is removed upstream but it's modified locally, so it's kept.
+	UpgradeKept = "kept"
+)
+
+// UpgradedFile is a file which is affected by an upgrade.
+type UpgradedFile struct {
+	// Path is the slash-separated path of the file, relative to the project's directory.
+	Path string
+	// Status is one of the UpgradeXXX statuses, e.g. `UpgradeConflict`.
+	Status string
+}
+
+// Upgrade same as `UpgradeContext` with a background context.
+func (p *Project) Upgrade() ([]UpgradedFile, error) {
+	return p.UpgradeContext(context.Background())
+}
+
+// UpgradeContext re-applies the project's repository, usually at a newer `Version`, over an installed
+// project of `ReadManifest` and returns the affected files. Clear the `Checksum` to accept a different archive.
+//
+// It's a three-way comparison of the files recorded by the manifest, the upstream files and the local ones:
+// only the files which are changed upstream are written and the locally modified files are never overwritten,
+// see the UpgradeXXX statuses. The manifest is updated with the upstream files on success.
+// Symbolic links are not upgraded.
+func (p *Project) UpgradeContext(ctx context.Context) ([]UpgradedFile, error) {
+	if p.Installed == nil {
+		return nil, fmt.Errorf("project <%s> has no installed files, please use ReadManifest", p.Dest)
+	}
+
+	dir := resolveDest(p.Dest, p.Module, p.GOPATH)
+
+	tmp, err := ioutil.TempDir("", "iris-cli-upgrade")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err = p.installCopy(ctx, tmp); err != nil {
+		return nil, err
+	}
+
+	upstream, err := ReadManifest(tmp)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		upgraded  []UpgradedFile
+		baseDir   string // the base files of the conflicts, installed on the first one.
+		baseTried bool
+	)
+	for _, name := range fileNames(p.Installed, upstream.Installed) {
+		var (
+			base         = p.Installed[name]
+			newHash      = upstream.Installed[name]
+			fpath        = filepath.Join(dir, filepath.FromSlash(name))
+			local, _     = fileChecksum(fpath) // empty if missing.
+			upstreamPath = filepath.Join(tmp, filepath.FromSlash(name))
+			status       string
+		)
+
+		switch {
+		case newHash == local || newHash == base:
+			// Up to date or not changed upstream.
+			continue
+		case newHash == "":
+			if local == "" {
+				continue
+			}
+
+			if local == base {
+				if err = os.Remove(fpath); err != nil {
+					return upgraded, err
+				}
+				status = UpgradeRemoved
+			} else {
+				status = UpgradeKept
+			}
+		case local == base:
+			if err = copyFile(upstreamPath, fpath); err != nil {
+				return upgraded, err
+			}
+
+			status = UpgradeUpdated
+			if base == "" {
+				status = UpgradeAdded
+			}
+		default:
+			if err = copyFile(upstreamPath, fpath+".new"); err != nil {
+				return upgraded, err
+			}
+
+			if !baseTried {
+				baseTried = true
+				baseDir, _ = p.installBase(ctx, dir) // falls back to the local files.
+				defer os.RemoveAll(baseDir)
+			}
+
+			origPath := filepath.Join(baseDir, filepath.FromSlash(name))
+			if baseDir == "" || !utils.Exists(origPath) {
+				origPath = fpath // the local contents, e.g. of a file which is added both upstream and locally.
+			}
+
+			if err = copyFile(origPath, fpath+".orig"); err != nil {
+				return upgraded, err
+			}
+			status = UpgradeConflict
+		}
+
+		upgraded = append(upgraded, UpgradedFile{Path: name, Status: status})
+	}
+
+	p.Dest = dir
+	p.Module = upstream.Module
+	p.Installed = upstream.Installed
+	return upgraded, p.writeManifest(upstream.Checksum)
+}
+
+// installCopy installs a copy of the project to the "dest" directory without the post install steps.
+func (p *Project) installCopy(ctx context.Context, dest string) error {
+	next := *p
+	next.Dest = dest
+	next.FS = nil // the temporary directory is on the OS file system.
+	next.Overwrite = OverwriteForce
+	next.DryRun = false
+	next.Tidy, next.Build, next.Gitignore, next.GitInit = false, false, false, false
+	next.Confirm = nil // the post install commands are not run again.
+	_, err := next.install(ctx)
+	return err
+}
+
+// installBase installs the project at the version and checksum of the manifest of "dir", i.e. the base of an upgrade,
+// to a temporary directory and returns it. On failure, the directory is removed and it returns an empty one.
+func (p *Project) installBase(ctx context.Context, dir string) (string, error) {
+	installed, err := ReadManifest(dir)
+	if err != nil {
+		return "", err
+	}
+
+	tmp, err := ioutil.TempDir("", "iris-cli-upgrade-base")
+	if err != nil {
+		return "", err
+	}
+
+	base := *p
+	base.Version, base.Checksum = installed.Version, installed.Checksum
+	if err = base.installCopy(ctx, tmp); err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+
+	return tmp, nil
+}
+
+// fileNames returns the sorted, unique, keys of the "files" maps.
+func fileNames(files ...map[string]string) []string {
+	var names []string
+	seen := make(map[string]struct{})
+	for _, m := range files {
+		for name := range m {
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// copyFile copies the "src" file to "dst" with the same permissions, its parent directories are created if missing.
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	b, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(dst, b, info.Mode())
+}
@@ -0,0 +1,100 @@
+package project
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+func TestProjectUpgrade(t *testing.T) {
+	v1 := newTestArchive(t,
+		testFile{"project-v1/main.go", "package main\n"},
+		testFile{"project-v1/a.go", "package main\n\n// a v1\n"},
+		testFile{"project-v1/b.go", "package main\n\n// b v1\n"},
+		testFile{"project-v1/c.go", "package main\n\n// c v1\n"},
+		testFile{"project-v1/e.go", "package main\n\n// e v1\n"},
+		testFile{"project-v1/go.mod", "module github.com/author/project\n"},
+	)
+	v2 := newTestArchive(t,
+		testFile{"project-v2/main.go", "package main\n"},
+		testFile{"project-v2/a.go", "package main\n\n// a v2\n"},
+		testFile{"project-v2/b.go", "package main\n\n// b v2\n"},
+		testFile{"project-v2/c.go", "package main\n\n// c v1\n"},
+		testFile{"project-v2/d/d.go", "package d\n\nimport _ \"github.com/author/project/sub\"\n"},
+		testFile{"project-v2/go.mod", "module github.com/author/project\n"},
+	)
+
+	repo, closeProvider := newTestProviderHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "v2.zip") {
+			w.Write(v2)
+			return
+		}
+		w.Write(v1)
+	})
+	defer closeProvider()
+
+	dest := newTestDest(t)
+	defer os.RemoveAll(dest)
+
+	p := New("project", repo+"@v1")
+	p.Dest = dest
+	p.Module = "newproject"
+	if err := p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Local changes.
+	for name, contents := range map[string]string{"b.go": "package main\n\n// b local\n", "c.go": "package main\n\n// c local\n"} {
+		if err := ioutil.WriteFile(filepath.Join(dest, name), []byte(contents), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	installed, err := ReadManifest(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	installed.Version = "v2"
+	installed.Checksum = ""
+	installed.Build = true // the upstream files are not built, a main package without a main function fails.
+	upgraded, err := installed.Upgrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []UpgradedFile{
+		{"a.go", UpgradeUpdated},
+		{"b.go", UpgradeConflict},
+		{"d/d.go", UpgradeAdded},
+		{"e.go", UpgradeRemoved},
+	}
+	if !reflect.DeepEqual(expected, upgraded) {
+		t.Fatalf("expected upgraded files:\n%v\nbut got:\n%v", expected, upgraded)
+	}
+
+	expectFile(t, filepath.Join(dest, "a.go"), "package main\n\n// a v2\n")
+	expectFile(t, filepath.Join(dest, "b.go"), "package main\n\n// b local\n")
+	expectFile(t, filepath.Join(dest, "b.go.new"), "package main\n\n// b v2\n")
+	expectFile(t, filepath.Join(dest, "b.go.orig"), "package main\n\n// b v1\n")
+	expectFile(t, filepath.Join(dest, "c.go"), "package main\n\n// c local\n")
+	expectFile(t, filepath.Join(dest, "d", "d.go"), "package d\n\nimport _ \"newproject/sub\"\n")
+	if utils.Exists(filepath.Join(dest, "e.go")) {
+		t.Fatalf("expected e.go to be removed")
+	}
+
+	upgradedManifest, err := ReadManifest(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if upgradedManifest.Version != "v2" || upgradedManifest.Installed["b.go"] != sha256Hex("package main\n\n// b v2\n") {
+		t.Fatalf("expected the manifest to be updated but got:\n%#+v", upgradedManifest)
+	}
+}
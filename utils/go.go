@@ -2,9 +2,15 @@ package utils
 
 import (
 	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -19,34 +25,85 @@ func Package(b []byte) []byte {
 	return parseDeclaration(b, pkgBytes)
 }
 
+// GoVersion returns the go version of the "go 1.x" directive of a go.mod file "b" contents, e.g. "1.14".
+func GoVersion(b []byte) string {
+	return string(parseDeclaration(b, goBytes))
+}
+
 var (
-	slashSlash            = []byte("//")
-	multilineCommentStart = []byte("/*")
-	multilineCommentEnd   = []byte("*/")
-	moduleBytes           = []byte("module")
-	pkgBytes              = []byte("package")
+	goBytes     = []byte("go")
+	moduleBytes = []byte("module")
+	pkgBytes    = []byte("package")
+	mainBytes   = []byte("main")
 )
 
+// stripComments returns a copy of "b" with its "//" and "/* */" comments replaced by spaces.
+// The new lines and the length are kept, so offsets of the result match the "b" ones.
+func stripComments(b []byte) []byte {
+	out := make([]byte, len(b))
+	copy(out, b)
+
+	var (
+		quote        byte // the string's quote, if inside a string.
+		lineComment  bool
+		blockComment bool
+	)
+
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch {
+		case lineComment:
+			if c == '\n' {
+				lineComment = false
+				continue
+			}
+		case blockComment:
+			if c == '*' && i+1 < len(out) && out[i+1] == '/' {
+				blockComment = false
+				out[i], out[i+1] = ' ', ' '
+				i++
+				continue
+			}
+			if c == '\n' {
+				continue
+			}
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++ // skip the escaped character.
+			} else if c == quote || (c == '\n' && quote == '"') {
+				quote = 0
+			}
+			continue
+		case c == '"' || c == '`':
+			quote = c
+			continue
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			lineComment = true
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			blockComment = true
+			out[i], out[i+1] = ' ', ' '
+			i++
+			continue
+		default:
+			continue
+		}
+
+		out[i] = ' '
+	}
+
+	return out
+}
+
 // parseDeclaration returns the "delcarion $TEXT" of "b" contents.
+// Declarations inside comments are ignored.
 func parseDeclaration(b []byte, declaration []byte) []byte {
+	b = stripComments(b)
 	for len(b) > 0 {
 		line := b
 		b = nil
 		if i := bytes.IndexByte(line, '\n'); i >= 0 {
 			line, b = line[:i], line[i+1:]
 		}
-		if i := bytes.Index(line, slashSlash); i >= 0 {
-			line = line[:i]
-		}
-
-		// skip /* */ comment lines.
-		if i := bytes.Index(line, multilineCommentStart); i >= 0 {
-			i = bytes.Index(b, multilineCommentEnd)
-			if i > 0 {
-				b = b[i:]
-			}
-			continue
-		}
 
 		line = bytes.TrimSpace(line)
 		if !bytes.HasPrefix(line, declaration) {
@@ -73,8 +130,405 @@ func parseDeclaration(b []byte, declaration []byte) []byte {
 	return nil
 }
 
+// GoModRequires returns the required modules of a go.mod file "b" contents,
+// as "path@version", e.g. "github.com/kataras/iris/v12@v12.1.8".
+// Both the single line and the block forms of the require directive are supported,
+// comments (e.g. "// indirect") and other directives, e.g. replace and exclude, are ignored.
+func GoModRequires(b []byte) (requires []string) {
+	for _, r := range goModRequires(b) {
+		requires = append(requires, r.path+"@"+r.version)
+	}
+
+	return
+}
+
+// SetGoModRequire returns a copy of the go.mod file "b" contents with the version of the "modulePath"
+// require directive replaced by "version" and the previous version, the rest of the file is kept as it is.
+// It fails if the "modulePath" is not required.
+func SetGoModRequire(b []byte, modulePath, version string) ([]byte, string, error) {
+	for _, r := range goModRequires(b) {
+		if r.path != modulePath {
+			continue
+		}
+
+		end := r.offset + len(r.version)
+		contents := make([]byte, 0, len(b)-len(r.version)+len(version))
+		contents = append(contents, b[:r.offset]...)
+		contents = append(contents, version...)
+		contents = append(contents, b[end:]...)
+		return contents, r.version, nil
+	}
+
+	return nil, "", fmt.Errorf("module <%s> is not required", modulePath)
+}
+
+// goModRequire is a require directive's module of a go.mod file.
+type goModRequire struct {
+	path    string
+	version string
+	offset  int // the offset of the version in the go.mod contents.
+}
+
+// goModRequires returns the require directives' modules of a go.mod file "b" contents, see `GoModRequires`.
+func goModRequires(b []byte) (requires []goModRequire) {
+	for _, line := range goModDirectives(b, "require") {
+		if len(line.fields) != 2 {
+			continue
+		}
+
+		modulePath := line.fields[0]
+		if p, err := strconv.Unquote(modulePath); err == nil {
+			modulePath = p
+		}
+
+		requires = append(requires, goModRequire{path: modulePath, version: line.fields[1], offset: line.offsets[1]})
+	}
+
+	return
+}
+
+// goModLine is a line of a go.mod directive, without its verb, e.g. "github.com/kataras/iris/v12 v12.1.8".
+type goModLine struct {
+	fields  []string
+	offsets []int // the offsets of the fields in the go.mod contents.
+	end     int   // the offset of the line's end, before its comment.
+}
+
+// goModDirectives returns the lines of the "verb" directives of a go.mod file "b" contents,
+// both the single line and the block forms. Comments are ignored.
+func goModDirectives(b []byte, verb string) (lines []goModLine) {
+	inBlock := false
+	offset := 0
+	for _, line := range bytes.Split(stripComments(b), []byte("\n")) {
+		lineOffset := offset
+		offset += len(line) + 1
+
+		fields, offsets := lineFields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if inBlock {
+			if fields[0] == ")" {
+				inBlock = false
+				continue
+			}
+		} else {
+			if fields[0] != verb {
+				continue
+			}
+
+			if fields, offsets = fields[1:], offsets[1:]; len(fields) == 1 && fields[0] == "(" {
+				inBlock = true
+				continue
+			}
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		for i := range offsets {
+			offsets[i] += lineOffset
+		}
+
+		last := len(fields) - 1
+		lines = append(lines, goModLine{fields: fields, offsets: offsets, end: offsets[last] + len(fields[last])})
+	}
+
+	return
+}
+
+// SetGoModReplace returns a copy of the go.mod file "b" contents with the replace directive of "oldPath"
+// set to "newPath", e.g. "github.com/kataras/iris/v12" => "../iris" or "example.com/mirror/iris/v12 v12.2.0".
+// The "oldPath" may contain a version too, e.g. "github.com/kataras/iris/v12 v12.1.8", to replace only that version.
+// An existing directive of the same module (and version) is updated in place, inside a block or not,
+// otherwise a new one is appended to the end of the file. The rest of the file is kept as it is.
+func SetGoModReplace(b []byte, oldPath, newPath string) ([]byte, error) {
+	old, replacement := strings.Fields(oldPath), strings.Fields(newPath)
+	if len(old) == 0 || len(old) > 2 {
+		return nil, fmt.Errorf("invalid replaced module <%s>: expected a module path and an optional version", oldPath)
+	}
+	if len(replacement) == 0 || len(replacement) > 2 {
+		return nil, fmt.Errorf("invalid replacement <%s>: expected a directory or a module path and a version", newPath)
+	}
+
+	newPath = strings.Join(replacement, " ")
+	for _, line := range goModDirectives(b, "replace") {
+		arrow := -1
+		for i, field := range line.fields {
+			if field == "=>" {
+				arrow = i
+				break
+			}
+		}
+
+		if arrow < 1 || arrow == len(line.fields)-1 || !equalModuleFields(line.fields[:arrow], old) {
+			continue
+		}
+
+		start := line.offsets[arrow+1]
+		contents := make([]byte, 0, len(b)-(line.end-start)+len(newPath))
+		contents = append(contents, b[:start]...)
+		contents = append(contents, newPath...)
+		contents = append(contents, b[line.end:]...)
+		return contents, nil
+	}
+
+	contents := make([]byte, 0, len(b)+len(oldPath)+len(newPath)+len("\nreplace  => \n"))
+	contents = append(contents, b...)
+	if len(contents) > 0 && contents[len(contents)-1] != '\n' {
+		contents = append(contents, '\n')
+	}
+	contents = append(contents, "\nreplace "+strings.Join(old, " ")+" => "+newPath+"\n"...)
+	return contents, nil
+}
+
+// equalModuleFields reports whether the "fields" of a go.mod line, e.g. a quoted module path and a version,
+// are equal to the "expected" ones.
+func equalModuleFields(fields, expected []string) bool {
+	if len(fields) != len(expected) {
+		return false
+	}
+
+	for i, field := range fields {
+		if p, err := strconv.Unquote(field); err == nil {
+			field = p
+		}
+
+		if field != expected[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// lineFields returns the space-separated fields of the "line" and their offsets.
+func lineFields(line []byte) (fields []string, offsets []int) {
+	start := -1
+	for i := 0; i <= len(line); i++ {
+		if i == len(line) || line[i] == ' ' || line[i] == '\t' || line[i] == '\r' {
+			if start >= 0 {
+				fields = append(fields, string(line[start:i]))
+				offsets = append(offsets, start)
+				start = -1
+			}
+			continue
+		}
+
+		if start == -1 {
+			start = i
+		}
+	}
+
+	return
+}
+
+// CheckModulePath reports whether "modulePath" is a valid go module path,
+// e.g. "github.com/author/project" or "project". It's a lightweight version of the
+// rules of the go command: the path elements are not empty and they contain only
+// ASCII letters, digits and the "-._~" characters, they don't start or end with a dot
+// and they are not reserved file names on Windows. A leading domain-like element, with a dot,
+// must be lowercase and not start with a dash and a major version suffix must be "/v2" or greater.
+func CheckModulePath(modulePath string) error {
+	if modulePath == "" {
+		return fmt.Errorf("empty module path")
+	}
+
+	elems := strings.Split(modulePath, "/")
+	for _, elem := range elems {
+		if elem == "" {
+			return fmt.Errorf("module path <%s> has a leading, trailing or double slash", modulePath)
+		}
+
+		if elem == "." || elem == ".." {
+			return fmt.Errorf("module path <%s> has a dot path element", modulePath)
+		}
+
+		for _, r := range elem {
+			if !isModulePathChar(r) {
+				return fmt.Errorf("module path <%s> has an invalid character %q", modulePath, r)
+			}
+		}
+
+		if elem[0] == '.' || elem[len(elem)-1] == '.' {
+			return fmt.Errorf("module path <%s> has an element <%s> which starts or ends with a dot", modulePath, elem)
+		}
+
+		if isWindowsReservedName(elem) {
+			return fmt.Errorf("module path <%s> has an element <%s> which is a reserved file name on Windows", modulePath, elem)
+		}
+	}
+
+	if first := elems[0]; strings.Contains(first, ".") {
+		if first[0] == '-' {
+			return fmt.Errorf("module path <%s> has a leading dash in its domain <%s>", modulePath, first)
+		}
+
+		for _, r := range first {
+			if !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '-' || r == '.') {
+				return fmt.Errorf("module path <%s> has an invalid character %q in its domain <%s>, it should be lowercase", modulePath, r, first)
+			}
+		}
+	}
+
+	if last := elems[len(elems)-1]; len(elems) > 1 && isMajorVersion(last) {
+		if last == "v0" || last == "v1" || last[1] == '0' {
+			return fmt.Errorf("module path <%s> has an invalid major version suffix <%s>, it should be v2 or greater", modulePath, last)
+		}
+	}
+
+	return nil
+}
+
+func isModulePathChar(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' ||
+		r == '-' || r == '.' || r == '_' || r == '~'
+}
+
+// IsCanonicalVersion reports whether the "version" is a canonical semantic version of a module,
+// e.g. "v1.2.3", "v1.2.3-rc.1" or "v2.0.0+incompatible", instead of a query like "v1.2", a branch or a commit.
+func IsCanonicalVersion(version string) bool {
+	if !strings.HasPrefix(version, "v") {
+		return false
+	}
+
+	v := version[1:]
+	if i := strings.IndexAny(v, "-+"); i > 0 {
+		v = v[:i]
+	}
+
+	numbers := strings.Split(v, ".")
+	if len(numbers) != 3 {
+		return false
+	}
+
+	for _, n := range numbers {
+		if n == "" {
+			return false
+		}
+
+		for _, r := range n {
+			if r < '0' || r > '9' {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// ModuleName returns the last element of the "module" path without its major version suffix,
+// which is the name of the executable that "go build" outputs, e.g. "app" for "github.com/author/app/v2".
+func ModuleName(module string) string {
+	name := path.Base(module)
+	if dir := path.Dir(module); dir != "." && isMajorVersion(name) {
+		name = path.Base(dir)
+	}
+
+	return name
+}
+
+// isMajorVersion reports whether the module path element "elem" looks like a major version suffix, e.g. "v2".
+func isMajorVersion(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+
+	for _, r := range elem[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+var windowsReservedNames = []string{
+	"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+// isWindowsReservedName reports whether the "elem", without its extension, is a reserved file name on Windows.
+func isWindowsReservedName(elem string) bool {
+	if i := strings.IndexByte(elem, '.'); i >= 0 {
+		elem = elem[:i]
+	}
+
+	for _, name := range windowsReservedNames {
+		if strings.EqualFold(elem, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReplaceModulePath returns the "b" go.mod contents with their module declaration set to "newModule".
+// Other lines, e.g. a replace directive which refers to the current module, are kept as they are.
+func ReplaceModulePath(b []byte, newModule string) []byte {
+	oldModule := ModulePath(b)
+	if len(oldModule) == 0 {
+		return b
+	}
+
+	offset := 0
+	for _, line := range bytes.SplitAfter(stripComments(b), []byte("\n")) {
+		if bytes.Equal(parseDeclaration(line, moduleBytes), oldModule) {
+			i := offset + bytes.Index(line, oldModule)
+			return joinBytes(b[:i], []byte(newModule), b[i+len(oldModule):])
+		}
+
+		offset += len(line)
+	}
+
+	return b
+}
+
+// ReplaceImportPaths returns the "src" go source code with its import paths of the "oldModule",
+// the module itself and its packages, rewritten to "newModule".
+// Other occurrences of the "oldModule", e.g. string literals or a different module which
+// starts with the same path, are kept as they are.
+// If "src" can't be parsed then it's returned as it is.
+func ReplaceImportPaths(src []byte, oldModule, newModule string) []byte {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly)
+	if err != nil {
+		return src
+	}
+
+	// Replace from the end so the offsets of the previous imports are still valid.
+	for i := len(f.Imports) - 1; i >= 0; i-- {
+		lit := f.Imports[i].Path
+		importPath, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			continue
+		}
+
+		if importPath != oldModule && !strings.HasPrefix(importPath, oldModule+"/") {
+			continue
+		}
+
+		newPath := strconv.Quote(newModule + strings.TrimPrefix(importPath, oldModule))
+		start := fset.Position(lit.Pos()).Offset
+		end := fset.Position(lit.End()).Offset
+		src = joinBytes(src[:start], []byte(newPath), src[end:])
+	}
+
+	return src
+}
+
+func joinBytes(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
 // TryFindPackage returns a go package based on the dir,
-// it reads the package declaration of the `main.go` or any `*go`
+// it reads the package declaration of the `main.go` or any `*go`.
+// Test files are ignored and if the files declare different packages then
+// the most declared one wins, files with build constraints are counted
+// only if all files have them.
 func TryFindPackage(dir string) (pkg []byte) {
 	ignoreFilename := ""
 	if Ext(dir) != "" { // could use os.Stat but let's use just extension to decide if it's file because the "dir" may not exist yet.
@@ -83,16 +537,17 @@ func TryFindPackage(dir string) (pkg []byte) {
 		dir = filepath.Dir(dir)
 	}
 
-	d, err := os.Open(dir)
-	if err != nil {
-		return
-	}
-	files, err := d.Readdir(-1)
-	d.Close()
+	files, err := ioutil.ReadDir(dir) // sorted by filename.
 	if err != nil {
 		return
 	}
 
+	var (
+		counts            = make(map[string]int)
+		constrainedCounts = make(map[string]int)
+		order             []string // packages by first appearance, to resolve ties.
+	)
+
 	for _, f := range files {
 		if f.IsDir() { // read from the first level of directory only.
 			continue
@@ -103,18 +558,229 @@ func TryFindPackage(dir string) (pkg []byte) {
 			continue
 		}
 
-		if !strings.HasSuffix(fileName, ".go") { // read only go files.
+		if !strings.HasSuffix(fileName, ".go") || strings.HasSuffix(fileName, "_test.go") { // read only go, non-test, files.
 			continue
 		}
 
 		fpath := filepath.Join(dir, fileName)
 		b, err := ioutil.ReadFile(fpath)
-		if err == nil {
-			if pkg = Package(b); len(pkg) > 0 {
-				break
+		if err != nil {
+			continue
+		}
+
+		name := string(Package(b))
+		if name == "" {
+			continue
+		}
+
+		if hasBuildConstraint(b) {
+			constrainedCounts[name]++
+		} else {
+			counts[name]++
+		}
+
+		if counts[name]+constrainedCounts[name] == 1 {
+			order = append(order, name)
+		}
+	}
+
+	if len(counts) == 0 {
+		counts = constrainedCounts
+	}
+
+	max := 0
+	for _, name := range order {
+		if n := counts[name]; n > max {
+			max = n
+			pkg = []byte(name)
+		}
+	}
+
+	return
+}
+
+// hasBuildConstraint reports whether the "b" go source code has a build constraint,
+// i.e. a "//go:build" or "// +build" line before its package clause.
+func hasBuildConstraint(b []byte) bool {
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, []byte("//go:build ")) || bytes.HasPrefix(line, []byte("// +build ")) {
+			return true
+		}
+
+		if bytes.HasPrefix(line, pkgBytes) {
+			break
+		}
+	}
+
+	return false
+}
+
+// TryFindPackageRecursive same as `TryFindPackage` but it searches the subdirectories too,
+// up to "maxDepth" levels deep, zero means the "dir" only. A main package is preferred,
+// otherwise the first package found, level by level, is returned.
+// Hidden, vendor and testdata directories are ignored.
+func TryFindPackageRecursive(dir string, maxDepth int) (pkg []byte) {
+	if Ext(dir) != "" {
+		if pkg = TryFindPackage(dir); bytes.Equal(pkg, mainBytes) {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	dirs := []string{dir}
+	for depth := 0; depth <= maxDepth && len(dirs) > 0; depth++ {
+		var next []string
+		for _, d := range dirs {
+			if p := TryFindPackage(d); len(p) > 0 {
+				if bytes.Equal(p, mainBytes) {
+					return p
+				}
+
+				if len(pkg) == 0 {
+					pkg = p
+				}
+			}
+
+			files, err := ioutil.ReadDir(d)
+			if err != nil {
+				continue
+			}
+
+			for _, f := range files {
+				if name := f.Name(); f.IsDir() && !isIgnoredDir(name) {
+					next = append(next, filepath.Join(d, name))
+				}
 			}
 		}
+
+		dirs = next
 	}
 
 	return
 }
+
+func isIgnoredDir(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata"
+}
+
+// FindMainPackageDirs returns the directories, relative to the "root" and slash-separated,
+// of the main packages with a "func main", e.g. "." or "cmd/app", sorted by depth and name.
+// Test files, hidden, vendor and testdata directories are ignored.
+func FindMainPackageDirs(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.Walk(root, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if fpath != root && isIgnoredDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name := info.Name()
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			return nil
+		}
+
+		dir, err := filepath.Rel(root, filepath.Dir(fpath))
+		if err != nil {
+			return err
+		}
+		dir = filepath.ToSlash(dir)
+
+		if len(dirs) > 0 && dirs[len(dirs)-1] == dir {
+			return nil // already found, the files of a directory are walked in a row.
+		}
+
+		if hasMainFunc(fpath) {
+			dirs = append(dirs, dir)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	depth := func(dir string) int {
+		if dir == "." {
+			return 0
+		}
+		return strings.Count(dir, "/") + 1
+	}
+
+	// The directories are walked by name, so keep that order between the same depth.
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return depth(dirs[i]) < depth(dirs[j])
+	})
+
+	return dirs, nil
+}
+
+// FindMainPackageDir returns the first of the `FindMainPackageDirs`, the one closest to the "root".
+func FindMainPackageDir(root string) (string, error) {
+	dirs, err := FindMainPackageDirs(root)
+	if err != nil {
+		return "", err
+	}
+
+	if len(dirs) == 0 {
+		return "", fmt.Errorf("directory <%s> does not contain a main package", root)
+	}
+
+	return dirs[0], nil
+}
+
+// hasMainFunc reports whether the "fpath" go source file is of the main package and declares a "func main".
+func hasMainFunc(fpath string) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), fpath, nil, 0)
+	if err != nil || f.Name.Name != "main" {
+		return false
+	}
+
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+			return true
+		}
+	}
+
+	return false
+}
+
+const goGitignore = `# Binaries
+/bin/
+*.exe
+*.exe~
+*.dll
+*.so
+*.dylib
+*.test
+*.out
+
+# Dependencies
+# /vendor/
+node_modules/
+
+# Databases
+*.db
+
+# Environment
+.env
+.env.*
+!.env.example
+`
+
+// WriteGoGitignore writes a .gitignore file for Go and Iris projects inside "dir",
+// if one is not already present.
+func WriteGoGitignore(dir string) error {
+	fpath := filepath.Join(dir, ".gitignore")
+	if Exists(fpath) {
+		return nil
+	}
+
+	return ioutil.WriteFile(fpath, []byte(goGitignore), 0644)
+}
@@ -3,7 +3,10 @@ package utils
 import (
 	"bytes"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -48,3 +51,450 @@ func main() {}`)
 		t.Fatalf("expected %q but got %q", expected, got)
 	}
 }
+
+func TestReplaceModulePath(t *testing.T) {
+	contents := []byte(`// module comment
+module github.com/author/project
+
+require github.com/author/project/tools v0.0.1
+
+replace github.com/author/project => ./
+
+replace (
+	github.com/author/project/tools => ./tools
+	github.com/author/project v0.0.0 => ../project
+)
+`)
+
+	expected := []byte(`// module comment
+module newproject
+
+require github.com/author/project/tools v0.0.1
+
+replace github.com/author/project => ./
+
+replace (
+	github.com/author/project/tools => ./tools
+	github.com/author/project v0.0.0 => ../project
+)
+`)
+
+	if got := ReplaceModulePath(contents, "newproject"); !bytes.Equal(expected, got) {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+}
+
+func TestReplaceImportPaths(t *testing.T) {
+	src := []byte(`package main
+
+import (
+	"fmt"
+
+	"github.com/author/project"
+	sub "github.com/author/project/sub"
+	"github.com/author/projectzzz"
+)
+
+const repo = "github.com/author/project"
+
+func main() { fmt.Println(project.Name, sub.Name, projectzzz.Name, repo) }
+`)
+
+	expected := []byte(`package main
+
+import (
+	"fmt"
+
+	"newproject"
+	sub "newproject/sub"
+	"github.com/author/projectzzz"
+)
+
+const repo = "github.com/author/project"
+
+func main() { fmt.Println(project.Name, sub.Name, projectzzz.Name, repo) }
+`)
+
+	if got := ReplaceImportPaths(src, "github.com/author/project", "newproject"); !bytes.Equal(expected, got) {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+}
+
+func TestCheckModulePath(t *testing.T) {
+	tests := []struct {
+		path  string
+		valid bool
+	}{
+		{"github.com/author/project", true},
+		{"project", true},
+		{"github.com/author/project/v12", true},
+		{"", false},
+		{"/project", false},
+		{"github.com/author/project/", false},
+		{"github.com//project", false},
+		{"github.com/../project", false},
+		{"my project", false},
+		{"github.com/Author/Project", true},
+		{"GitHub.com/author/project", false},
+		{"-github.com/author/project", false},
+		{"github.com/author/.project", false},
+		{"github.com/author/project.", false},
+		{"github.com/author/con", false},
+		{"github.com/author/aux.go", false},
+		{"github.com/author/console", true},
+		{"github.com/author/project/v1", false},
+		{"github.com/author/project/v0", false},
+		{"github.com/author/project/v02", false},
+		{"github.com/author/project/v2", true},
+		{"github.com/author/v1/pkg", true},
+		{"v1", true},
+		{"github.com/author/project+x", false},
+	}
+
+	for _, tt := range tests {
+		if err := CheckModulePath(tt.path); tt.valid != (err == nil) {
+			t.Fatalf("[%s] expected valid: %v but got error: %v", tt.path, tt.valid, err)
+		}
+	}
+}
+
+func TestWriteGoGitignore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err = WriteGoGitignore(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	fpath := filepath.Join(dir, ".gitignore")
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(b, []byte("\n*.db\n")) {
+		t.Fatalf("expected a go .gitignore but got:\n%s", b)
+	}
+
+	// Existing files are kept.
+	if err = ioutil.WriteFile(fpath, []byte("custom\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = WriteGoGitignore(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if b, _ = ioutil.ReadFile(fpath); string(b) != "custom\n" {
+		t.Fatalf("expected the existing .gitignore to be kept but got:\n%s", b)
+	}
+}
+
+func TestTryFindPackageRecursive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for fpath, contents := range map[string]string{
+		"pkg/lib/lib.go":   "package lib\n",
+		"cmd/app/main.go":  "package main\n\nfunc main() {}\n",
+		"vendor/x/main.go": "package main\n",
+	} {
+		fpath = filepath.Join(dir, filepath.FromSlash(fpath))
+		if err = os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(fpath, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		maxDepth int
+		expected []byte
+	}{
+		{0, nil},
+		{1, nil},
+		{2, []byte("main")},
+	}
+
+	for _, tt := range tests {
+		if got := TryFindPackageRecursive(dir, tt.maxDepth); !bytes.Equal(tt.expected, got) {
+			t.Fatalf("[%d] expected %q but got %q", tt.maxDepth, tt.expected, got)
+		}
+	}
+
+	if expected, got := []byte("lib"), TryFindPackageRecursive(filepath.Join(dir, "pkg"), 1); !bytes.Equal(expected, got) {
+		t.Fatalf("expected %q but got %q", expected, got)
+	}
+}
+
+func TestModulePathComments(t *testing.T) {
+	tests := []string{
+		`/* module foo */
+module github.com/author/project
+`,
+		`/* module foo */ module github.com/author/project
+`,
+		`// module foo
+/*
+module bar
+*/
+module github.com/author/project // module baz
+`,
+		`/*
+	module foo /* not nested
+*/
+module "github.com/author/project"
+`,
+	}
+
+	for i, contents := range tests {
+		if expected, got := []byte("github.com/author/project"), ModulePath([]byte(contents)); !bytes.Equal(expected, got) {
+			t.Fatalf("[%d] expected %q but got %q", i, expected, got)
+		}
+
+		expected := strings.Replace(contents, "github.com/author/project", "newproject", 1)
+		if got := ReplaceModulePath([]byte(contents), "newproject"); expected != string(got) {
+			t.Fatalf("[%d] expected:\n%s\nbut got:\n%s", i, expected, got)
+		}
+	}
+}
+
+func TestTryFindPackageConstraints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, contents string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("a_windows.go", "//go:build windows\n// +build windows\n\npackage other\n")
+	if expected, got := []byte("other"), TryFindPackage(dir); !bytes.Equal(expected, got) {
+		t.Fatalf("expected %q but got %q", expected, got)
+	}
+
+	write("a_test.go", "package app_test\n")
+	write("b.go", "package app\n")
+	write("c.go", "package app\n")
+	write("d.go", "package x\n")
+	if expected, got := []byte("app"), TryFindPackage(dir); !bytes.Equal(expected, got) {
+		t.Fatalf("expected %q but got %q", expected, got)
+	}
+}
+
+func TestGoModRequires(t *testing.T) {
+	b := []byte(`module github.com/author/project
+
+go 1.14
+
+require github.com/kataras/iris/v12 v12.1.8 // a comment
+
+require (
+	// github.com/commented/out v1.0.0
+	github.com/BurntSushi/toml v0.3.1
+	golang.org/x/net v0.0.0-20200324143707-d3edc9973b7e // indirect
+	"gopkg.in/yaml.v2" v2.2.8
+)
+
+replace github.com/kataras/iris/v12 => ../iris
+
+exclude github.com/BurntSushi/toml v0.3.0
+`)
+
+	expected := []string{
+		"github.com/kataras/iris/v12@v12.1.8",
+		"github.com/BurntSushi/toml@v0.3.1",
+		"golang.org/x/net@v0.0.0-20200324143707-d3edc9973b7e",
+		"gopkg.in/yaml.v2@v2.2.8",
+	}
+
+	if got := GoModRequires(b); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected requires:\n%v\nbut got:\n%v", expected, got)
+	}
+}
+
+func TestGoVersion(t *testing.T) {
+	var tests = []struct {
+		contents string
+		expected string
+	}{
+		{"module github.com/author/project\n\ngo 1.14\n", "1.14"},
+		{"module github.com/author/project\n// go 1.12\n\ngo 1.13 // a comment\n", "1.13"},
+		{"module github.com/author/project\n\nrequire (\n\tgolang.org/x/net v0.0.0\n)\n\ngo\t1.15\n", "1.15"},
+		{"module github.com/author/project\n\nrequire golang.org/x/net v0.0.0\n", ""},
+		{"gopkg.in/yaml.v2 v2.2.8\ngo1.14\n", ""},
+	}
+
+	for i, tt := range tests {
+		if got := GoVersion([]byte(tt.contents)); tt.expected != got {
+			t.Fatalf("[%d] expected go version: %q but got: %q", i, tt.expected, got)
+		}
+	}
+}
+
+func TestSetGoModRequire(t *testing.T) {
+	b := []byte(`module github.com/author/project
+
+go 1.14
+
+require (
+	// github.com/kataras/iris/v12 v12.0.0
+	github.com/BurntSushi/toml v0.3.1
+	github.com/kataras/iris/v12 v12.1.8 // a comment
+)
+`)
+
+	contents, old, err := SetGoModRequire(b, "github.com/kataras/iris/v12", "v12.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "v12.1.8"; expected != old {
+		t.Fatalf("expected previous version: %s but got: %s", expected, old)
+	}
+
+	expected := strings.Replace(string(b), "v12 v12.1.8 // a comment", "v12 v12.2.0 // a comment", 1)
+	if got := string(contents); expected != got {
+		t.Fatalf("expected contents:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	if _, _, err = SetGoModRequire(b, "github.com/kataras/neffos", "v0.0.16"); err == nil {
+		t.Fatalf("expected an error for a module which is not required")
+	}
+}
+
+func TestSetGoModReplace(t *testing.T) {
+	b := []byte(`module github.com/author/project
+
+go 1.14
+
+require github.com/kataras/iris/v12 v12.1.8
+
+replace (
+	// github.com/kataras/neffos => ../old
+	"github.com/kataras/neffos" => ../neffos // local fork
+	github.com/kataras/golog v0.0.18 => example.com/mirror/golog v0.0.18
+)`)
+
+	tests := []struct {
+		oldPath  string
+		newPath  string
+		expected string
+	}{
+		{"github.com/kataras/neffos", "../fork/neffos", strings.Replace(string(b), "=> ../neffos //", "=> ../fork/neffos //", 1)},
+		{"github.com/kataras/golog v0.0.18", "../golog", strings.Replace(string(b), "=> example.com/mirror/golog v0.0.18", "=> ../golog", 1)},
+		{"github.com/kataras/golog", "../golog", string(b) + "\n\nreplace github.com/kataras/golog => ../golog\n"},
+		{"github.com/kataras/iris/v12", "example.com/mirror/iris/v12  v12.2.0", string(b) + "\n\nreplace github.com/kataras/iris/v12 => example.com/mirror/iris/v12 v12.2.0\n"},
+	}
+
+	for _, tt := range tests {
+		contents, err := SetGoModReplace(b, tt.oldPath, tt.newPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := string(contents); tt.expected != got {
+			t.Fatalf("[%s] expected contents:\n%s\nbut got:\n%s", tt.oldPath, tt.expected, got)
+		}
+	}
+
+	if _, err := SetGoModReplace(b, "github.com/kataras/neffos", ""); err == nil {
+		t.Fatalf("expected an error for an empty replacement")
+	}
+}
+
+func TestFindMainPackageDirs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err = FindMainPackageDir(dir); err == nil {
+		t.Fatalf("expected an error when there is no main package")
+	}
+
+	for fpath, contents := range map[string]string{
+		"go.mod":                   "module github.com/author/app\n",
+		"cmd/server/internal/x.go": "package main\n\nfunc main() {}\n",
+		"cmd/worker/main.go":       "package main\n\nfunc main() {}\n",
+		"cmd/tool/tool.go":         "package main\n\nfunc run() {}\n",
+		"cmd/tool/tool_test.go":    "package main\n\nfunc main() {}\n",
+		"pkg/lib/lib.go":           "package lib\n\nfunc main() {}\n",
+		"pkg/lib/method.go":        "package lib\n\ntype T struct{}\n\nfunc (T) main() {}\n",
+		"app.go":                   "package main\n\nfunc main() {}\n",
+		"vendor/x/main.go":         "package main\n\nfunc main() {}\n",
+		"_examples/a/main.go":      "package main\n\nfunc main() {}\n",
+	} {
+		fpath = filepath.Join(dir, filepath.FromSlash(fpath))
+		if err = os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(fpath, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dirs, err := FindMainPackageDirs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{".", "cmd/worker", "cmd/server/internal"}; !reflect.DeepEqual(expected, dirs) {
+		t.Fatalf("expected: %v but got: %v", expected, dirs)
+	}
+
+	if got, err := FindMainPackageDir(filepath.Join(dir, "cmd")); err != nil || got != "worker" {
+		t.Fatalf("expected: worker but got: %s (%v)", got, err)
+	}
+}
+
+func TestModuleName(t *testing.T) {
+	tests := []struct {
+		module   string
+		expected string
+	}{
+		{"app", "app"},
+		{"github.com/author/app", "app"},
+		{"github.com/author/app/v2", "app"},
+		{"github.com/author/v2app", "v2app"},
+		{"v2", "v2"},
+	}
+
+	for _, tt := range tests {
+		if got := ModuleName(tt.module); tt.expected != got {
+			t.Fatalf("[%s] expected: %s but got: %s", tt.module, tt.expected, got)
+		}
+	}
+}
+
+func TestIsCanonicalVersion(t *testing.T) {
+	tests := map[string]bool{
+		"v12.1.8":              true,
+		"v1.2.3-rc.1":          true,
+		"v2.0.0+incompatible":  true,
+		"v0.0.0-20200501-abcd": true,
+		"v12.1":                false,
+		"12.1.8":               false,
+		"master":               false,
+		"v1.2.x":               false,
+		"v1..3":                false,
+	}
+
+	for version, expected := range tests {
+		if got := IsCanonicalVersion(version); expected != got {
+			t.Fatalf("[%s] expected: %v but got: %v", version, expected, got)
+		}
+	}
+}
@@ -1,13 +1,19 @@
 package utils
 
 import (
+	"bufio"
+	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // DownloadOption is the type of third, variadic input argument of the `Download` package-level function.
@@ -17,7 +23,13 @@ type DownloadOption func(*http.Request) error
 // Download returns the body of "url".
 // It uses the `http.DefaultClient` to download the resource specified by the "url" input argument.
 func Download(url string, body io.Reader, options ...DownloadOption) ([]byte, error) {
-	r, err := DownloadReader(url, body, options...)
+	return DownloadContext(context.Background(), nil, url, body, options...)
+}
+
+// DownloadContext same as `Download` but it accepts a context which can cancel the request
+// and the http "client" which sends it, if nil then the `http.DefaultClient` is used.
+func DownloadContext(ctx context.Context, client *http.Client, url string, body io.Reader, options ...DownloadOption) ([]byte, error) {
+	r, err := DownloadReaderContext(ctx, client, url, body, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -27,7 +39,13 @@ func Download(url string, body io.Reader, options ...DownloadOption) ([]byte, er
 
 // DownloadReader returns a response reader.
 func DownloadReader(url string, body io.Reader, options ...DownloadOption) (io.ReadCloser, error) {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	return DownloadReaderContext(context.Background(), nil, url, body, options...)
+}
+
+// DownloadReaderContext same as `DownloadReader` but it accepts a context which can cancel the request,
+// including the reading of its body, and the http "client" which sends it, if nil then the `http.DefaultClient` is used.
+func DownloadReaderContext(ctx context.Context, client *http.Client, url string, body io.Reader, options ...DownloadOption) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -39,30 +57,113 @@ func DownloadReader(url string, body io.Reader, options ...DownloadOption) (io.R
 		}
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	// defer resp.Body.Close()
 	var reader io.ReadCloser = resp.Body
+	contentLength := resp.ContentLength
 
-	if code := resp.StatusCode; code < 200 || code >= 400 {
+	// A not modified response has no body, it's a response to a conditional request, e.g. with "If-None-Match".
+	if code := resp.StatusCode; code < 200 || code >= 400 || code == http.StatusNotModified {
 		reader.Close()
-		return nil, fmt.Errorf("resource not available <%s>: %s", url, resp.Status)
+		return nil, StatusError{URL: url, StatusCode: code, Status: resp.Status, Header: resp.Header}
 	}
 
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
-		gzipReader, err := gzip.NewReader(reader)
-		if err != nil {
-			reader.Close()
-			return nil, err
+		// Some servers send the header but not a gzip body, e.g. an already compressed zip archive,
+		// so decode it only if it starts with the gzip magic bytes.
+		buffered := bufio.NewReader(reader)
+		if magic, _ := buffered.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+			gzipReader, err := gzip.NewReader(buffered)
+			if err != nil {
+				reader.Close()
+				return nil, err
+			}
+
+			// defer gzipReader.Close()
+			reader = multiCloser{Reader: gzipReader, closers: []io.ReadCloser{gzipReader, reader}}
+			contentLength = -1 // the decoded length is unknown.
+		} else {
+			reader = multiCloser{Reader: buffered, closers: []io.ReadCloser{reader}}
+		}
+	}
+
+	return responseReader{ReadCloser: reader, contentLength: contentLength, header: resp.Header}, nil
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// responseReader is the result of `DownloadReader`, it keeps information about the response.
+type responseReader struct {
+	io.ReadCloser
+	contentLength int64
+	header        http.Header
+}
+
+// ContentLength returns the body length of a `DownloadReader` result, -1 if unknown.
+func ContentLength(r io.Reader) int64 {
+	if v, ok := r.(responseReader); ok {
+		return v.contentLength
+	}
+
+	return -1
+}
+
+// ResponseHeader returns the response headers of a `DownloadReader` result, nil if unknown.
+func ResponseHeader(r io.Reader) http.Header {
+	if v, ok := r.(responseReader); ok {
+		return v.header
+	}
+
+	return nil
+}
+
+// StatusError is returned from `Download` and `DownloadReader`
+// when the server responds with a non-successful status code.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+}
+
+func (err StatusError) Error() string {
+	return fmt.Sprintf("resource not available <%s>: %s", err.URL, err.Status)
+}
+
+// IsStatus reports whether an "err" is caused because of a non-successful response
+// and returns its status code.
+func IsStatus(err error) (int, bool) {
+	if err != nil {
+		if v, ok := err.(StatusError); ok {
+			return v.StatusCode, true
 		}
+	}
+
+	return 0, false
+}
+
+// IsRateLimited reports whether an "err" is caused because the rate limit of an API is exceeded,
+// i.e. a response with a "X-RateLimit-Remaining: 0" header, and returns the time which the limit resets,
+// from the "X-RateLimit-Reset" header (in unix seconds), zero if unknown.
+func IsRateLimited(err error) (time.Time, bool) {
+	var statusErr StatusError
+	if !errors.As(err, &statusErr) || statusErr.Header.Get("X-RateLimit-Remaining") != "0" {
+		return time.Time{}, false
+	}
 
-		// defer gzipReader.Close()
-		reader = multiCloser{Reader: gzipReader, closers: []io.ReadCloser{gzipReader, reader}}
+	var reset time.Time
+	if seconds, err := strconv.ParseInt(statusErr.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		reset = time.Unix(seconds, 0)
 	}
 
-	return reader, nil
+	return reset, true
 }
 
 // ListReleases lists all releases of a github "repo".
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDownloadGzip(t *testing.T) {
+	body := []byte("PK\x03\x04 not a gzip body")
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write(body)
+	gw.Close()
+
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{"gzip", gzipped.Bytes()},
+		{"claims gzip", body},
+		{"empty", nil},
+	}
+
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(tt.body)
+		}))
+
+		got, err := Download(srv.URL, nil)
+		srv.Close()
+		if err != nil {
+			t.Fatalf("[%s] %v", tt.name, err)
+		}
+
+		expected := body
+		if tt.body == nil {
+			expected = nil
+		}
+
+		if !bytes.Equal(expected, got) {
+			t.Fatalf("[%s] expected body: %q but got: %q", tt.name, expected, got)
+		}
+	}
+}
+
+func TestIsRateLimited(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   http.Header
+		limited  bool
+		expected time.Time
+	}{
+		{"limited", http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"1600000000"}}, true, time.Unix(1600000000, 0)},
+		{"unknown reset", http.Header{"X-Ratelimit-Remaining": {"0"}}, true, time.Time{}},
+		{"remaining", http.Header{"X-Ratelimit-Remaining": {"10"}}, false, time.Time{}},
+		{"forbidden", http.Header{}, false, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for k, v := range tt.header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(http.StatusForbidden)
+		}))
+
+		_, err := Download(srv.URL, nil)
+		srv.Close()
+
+		reset, limited := IsRateLimited(fmt.Errorf("wrapped: %w", err))
+		if tt.limited != limited {
+			t.Fatalf("[%s] expected rate limited: %v but got: %v (%v)", tt.name, tt.limited, limited, err)
+		}
+
+		if !tt.expected.Equal(reset) {
+			t.Fatalf("[%s] expected reset: %s but got: %s", tt.name, tt.expected, reset)
+		}
+	}
+}
@@ -1,12 +1,16 @@
 package utils
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"mime"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/mattn/go-isatty"
 )
 
 func init() {
@@ -20,6 +24,13 @@ func init() {
 	mime.AddExtensionType(".tml", "application/tml; charset=utf-8")
 }
 
+// IsTerminal reports whether the "f" is a terminal, e.g. the `os.Stdin` of an interactive session.
+// Other character devices, e.g. /dev/null, are not terminals.
+func IsTerminal(f *os.File) bool {
+	fd := f.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
 // NoOpReadCloser wraps the "r" and returns a new io.ReadCloser which its `Close` does nothing.
 func NoOpReadCloser(r io.Reader) io.ReadCloser {
 	return noOpCloser{r}
@@ -31,6 +42,52 @@ type noOpCloser struct {
 
 func (r noOpCloser) Close() error { return nil }
 
+// ProgressReader wraps the "r" and returns a new io.Reader which reports the read bytes to "progress".
+// The "total" is the expected length of "r", -1 if unknown.
+// When "r" is fully read the "progress" is called with a "total" equal to "current".
+func ProgressReader(r io.Reader, total int64, progress func(current, total int64)) io.Reader {
+	return &progressReader{Reader: r, total: total, progress: progress}
+}
+
+type progressReader struct {
+	io.Reader
+	current  int64
+	total    int64
+	progress func(current, total int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.current += int64(n)
+
+	if err == io.EOF {
+		r.progress(r.current, r.current)
+	} else if n > 0 {
+		r.progress(r.current, r.total)
+	}
+
+	return n, err
+}
+
+// ContextReader wraps the "r" and returns a new io.Reader which fails with the "ctx" error once it's done,
+// e.g. to stop copying a large file when the operation is canceled.
+func ContextReader(ctx context.Context, r io.Reader) io.Reader {
+	return &contextReader{Reader: r, ctx: ctx}
+}
+
+type contextReader struct {
+	io.Reader
+	ctx context.Context
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.Reader.Read(p)
+}
+
 type multiCloser struct {
 	io.Reader
 	closers []io.ReadCloser
@@ -50,6 +107,16 @@ func (r multiCloser) Close() (err error) {
 	return
 }
 
+// IsBinary reports whether the "b" contents look like a binary file's ones,
+// a null byte in the first 8000 bytes is a strong sign of it, same check as git's one.
+func IsBinary(b []byte) bool {
+	if len(b) > 8000 {
+		b = b[:8000]
+	}
+
+	return bytes.IndexByte(b, 0) != -1
+}
+
 // Exists tries to report whether the local physical "path" exists.
 func Exists(path string) bool {
 	if _, err := os.Stat(path); err != nil && os.IsNotExist(err) {
@@ -74,15 +141,27 @@ func Ext(s string) string {
 }
 
 // Dest tries to resolve and returns a destination dir path.
+// The "%GOPATH%" is replaced with the $GOPATH/src directory, a leading "~" with the home directory
+// and the environment variables, e.g. "$HOME" or "${HOME}", are expanded.
 func Dest(dest string) string {
 	if dest == "" {
 		dest, _ = os.Getwd()
-	} else if s := "%GOPATH%"; strings.Contains(dest, s) {
-		gopath := os.Getenv("GOPATH")
-		if gopath != "" {
-			gopath = filepath.Join(gopath, "src")
-			dest = strings.Replace(dest, s, gopath, 1)
+	} else {
+		if s := "%GOPATH%"; strings.Contains(dest, s) {
+			gopath := os.Getenv("GOPATH")
+			if gopath != "" {
+				gopath = filepath.Join(gopath, "src")
+				dest = strings.Replace(dest, s, gopath, 1)
+			}
 		}
+
+		if dest == "~" || strings.HasPrefix(dest, "~/") || strings.HasPrefix(dest, "~"+string(os.PathSeparator)) {
+			if home, err := os.UserHomeDir(); err == nil {
+				dest = home + dest[1:]
+			}
+		}
+
+		dest = os.ExpandEnv(dest)
 	}
 
 	d, err := filepath.Abs(dest)
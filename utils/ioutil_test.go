@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDest(t *testing.T) {
+	for _, key := range []string{"HOME", "GOPATH", "IRIS_CLI_PROJECTS"} {
+		defer os.Setenv(key, os.Getenv(key))
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := filepath.Join(wd, "home")
+	gopath := filepath.Join(wd, "gopath")
+	os.Setenv("HOME", home)
+	os.Setenv("GOPATH", gopath)
+	os.Setenv("IRIS_CLI_PROJECTS", filepath.Join(home, "projects"))
+
+	tests := []struct {
+		dest     string
+		expected string
+	}{
+		{"", wd},
+		{"./app", filepath.Join(wd, "app")},
+		{"~", home},
+		{"~/projects/app", filepath.Join(home, "projects", "app")},
+		{"./~app", filepath.Join(wd, "~app")},
+		{"$HOME/app", filepath.Join(home, "app")},
+		{"${HOME}/app", filepath.Join(home, "app")},
+		{"$IRIS_CLI_PROJECTS/app", filepath.Join(home, "projects", "app")},
+		{"%GOPATH%/github.com/author", filepath.Join(gopath, "src", "github.com", "author")},
+	}
+
+	for _, tt := range tests {
+		if got := Dest(tt.dest); tt.expected != got {
+			t.Fatalf("[%s] expected destination: %s but got %s", tt.dest, tt.expected, got)
+		}
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if IsTerminal(f) {
+		t.Fatalf("expected %s to not be a terminal", os.DevNull)
+	}
+}